@@ -0,0 +1,82 @@
+package main
+
+import (
+	"ai-navigator/config"
+	"ai-navigator/exchange"
+	"ai-navigator/models"
+	"ai-navigator/store"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// runAwesome 实现 awesome 子命令：将站点数据渲染为 awesome 列表 Markdown，返回进程退出码
+//
+//	ai-navigator awesome [-o README.md] [-template awesome.md.tmpl] [-title 标题]
+func runAwesome(args []string) int {
+	cfg := config.AppConfig.Awesome
+
+	fs := flag.NewFlagSet("awesome", flag.ContinueOnError)
+	output := fs.String("o", "", "输出文件路径，默认输出到标准输出")
+	templatePath := fs.String("template", cfg.Template, "自定义模板文件路径，默认使用配置中的 awesome.template 或内置模板")
+	title := fs.String("title", cfg.Title, "一级标题")
+	printTemplate := fs.Bool("print-template", false, "输出内置模板后退出，可作为自定义模板的起点")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *printTemplate {
+		fmt.Print(exchange.DefaultAwesomeTemplate)
+		return 0
+	}
+
+	tmpl, err := exchange.ParseAwesomeTemplate(*templatePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	// 只读取数据，不拉取远程站点目录，也不改写站点包或数据库
+	siteStore, err := store.OpenReadOnly(config.AppConfig.Storage)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "打开站点存储失败: %v\n", err)
+		return 1
+	}
+	defer siteStore.Close()
+
+	sites, err := siteStore.List()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载站点数据失败: %v\n", err)
+		return 1
+	}
+	var categories []models.Category
+	if categoryStore, ok := siteStore.(store.CategoryStore); ok {
+		categories, err = categoryStore.ListCategories()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "加载分类数据失败: %v\n", err)
+			return 1
+		}
+		sites = models.WithCategoryNames(sites, categories)
+	}
+
+	// 先渲染到内存，模板出错时不会覆盖已有的输出文件
+	var buf bytes.Buffer
+	list := exchange.NewAwesomeList(*title, cfg.Description, sites, categories, time.Now())
+	if err := exchange.WriteAwesome(&buf, tmpl, list); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if *output == "" {
+		os.Stdout.Write(buf.Bytes())
+		return 0
+	}
+	if err := os.WriteFile(*output, buf.Bytes(), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "写入 %s 失败: %v\n", *output, err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "已生成 %s，共 %d 个分类、%d 个站点\n", *output, len(list.Categories), list.Total)
+	return 0
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -11,6 +12,10 @@ type Config struct {
 	Copyright string        `yaml:"copyright"`
 	Admin     AdminConfig   `yaml:"admin"`
 	Session   SessionConfig `yaml:"session"`
+	Storage   StorageConfig `yaml:"storage"`
+	Awesome   AwesomeConfig `yaml:"awesome"`
+	// PageSize 首页和搜索结果每页显示的站点数量，默认 60
+	PageSize int `yaml:"page_size"`
 }
 
 type AdminConfig struct {
@@ -22,6 +27,46 @@ type SessionConfig struct {
 	Secret string `yaml:"secret"`
 }
 
+// StorageConfig 站点数据存储配置
+type StorageConfig struct {
+	// Driver 存储后端: json（默认）或 sqlite
+	Driver string `yaml:"driver"`
+	// DataDir 数据文件所在目录，其中每个 JSON、YAML、CSV 文件都是一个站点包
+	DataDir string `yaml:"data_dir"`
+	// Packs 站点包的优先级，排在前面的包在 ID 重复时优先，未列出的包按文件名排在后面
+	Packs []string `yaml:"packs"`
+	// SQLitePath SQLite 数据库文件路径
+	SQLitePath string `yaml:"sqlite_path"`
+	// BackupKeep custom.json 保留的历史备份数量
+	BackupKeep int `yaml:"backup_keep"`
+	// TrashDays 回收站中的站点保留天数，超过后自动彻底删除，0 表示不自动清理
+	TrashDays int `yaml:"trash_days"`
+	// GitCommit 每次后台修改后在数据目录所在的 git 版本库中提交，仅 json 存储支持
+	GitCommit bool `yaml:"git_commit"`
+	// Remote 远程站点目录，配置后代替本地站点包
+	Remote RemoteConfig `yaml:"remote"`
+}
+
+// RemoteConfig 远程站点目录配置
+type RemoteConfig struct {
+	// URL 站点目录地址，为空时不启用，格式按扩展名或 Content-Type 识别
+	URL string `yaml:"url"`
+	// Interval 轮询间隔，默认 5 分钟
+	Interval time.Duration `yaml:"interval"`
+	// Timeout 单次请求超时，默认 10 秒
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// AwesomeConfig 导出 awesome 列表 Markdown 的配置
+type AwesomeConfig struct {
+	// Title 一级标题，默认 Awesome AI Tools
+	Title string `yaml:"title"`
+	// Description 标题下的简介
+	Description string `yaml:"description"`
+	// Template 自定义模板文件路径（Go text/template 语法），为空时使用内置模板
+	Template string `yaml:"template"`
+}
+
 var AppConfig Config
 
 func LoadConfig() error {
@@ -45,6 +90,11 @@ func LoadConfig() error {
 		Session: SessionConfig{
 			Secret: "your-secret-key-here",
 		},
+		Storage: StorageConfig{
+			Driver:     "json",
+			DataDir:    "./data",
+			BackupKeep: 10,
+		},
 	}
 	overrideFromEnv()
 	return nil
@@ -81,4 +131,16 @@ func overrideFromEnv() {
 	if secret := os.Getenv("SESSION_SECRET"); secret != "" {
 		AppConfig.Session.Secret = secret
 	}
+
+	if driver := os.Getenv("STORAGE_DRIVER"); driver != "" {
+		AppConfig.Storage.Driver = driver
+	}
+
+	if sqlitePath := os.Getenv("SQLITE_PATH"); sqlitePath != "" {
+		AppConfig.Storage.SQLitePath = sqlitePath
+	}
+
+	if remoteURL := os.Getenv("REMOTE_CATALOG_URL"); remoteURL != "" {
+		AppConfig.Storage.Remote.URL = remoteURL
+	}
 }
@@ -0,0 +1,222 @@
+package exchange
+
+import (
+	"ai-navigator/models"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+// DefaultAwesomeTitle 未配置标题时 awesome 列表使用的标题
+const DefaultAwesomeTitle = "Awesome AI Tools"
+
+// awesomeUncategorized 没有分类的站点所在的分组，排在最后
+const awesomeUncategorized = "其他"
+
+// DefaultAwesomeTemplate 内置的 awesome 列表模板，自定义模板可以从它修改
+const DefaultAwesomeTemplate = `# {{ .Title }}
+{{ if .Description }}
+{{ .Description }}
+{{ end }}
+> 共 {{ .Total }} 个站点，由站点数据自动生成于 {{ .GeneratedAt.Format "2006-01-02 15:04 MST" }}，请勿手动修改。
+
+## 目录
+
+{{ range .Categories }}- [{{ .Name }}](#{{ .Anchor }})
+{{ end }}{{ range .Categories }}
+## {{ .Name }}
+
+{{ range .Sites }}- [{{ md .Name }}]({{ .URL }}){{ if .Featured }} ⭐{{ end }}{{ if .Description }} - {{ md .Description }}{{ end }}{{ range .Tags }} ` + "`{{ . }}`" + `{{ end }}
+{{ end }}{{ end }}`
+
+// AwesomeList 渲染 awesome 列表模板时的数据
+type AwesomeList struct {
+	Title       string
+	Description string
+	GeneratedAt time.Time
+	// Total 站点总数
+	Total      int
+	Categories []AwesomeCategory
+}
+
+// AwesomeCategory awesome 列表中的一个分类
+type AwesomeCategory struct {
+	Name string
+	// Anchor 分类标题在 GitHub 上生成的锚点，用于目录链接
+	Anchor string
+	Sites  []models.Site
+}
+
+// NewAwesomeList 按分类分组站点，分类按分类树的顺序排列：上级分类在前，紧接着是它的子分类，同级按排序和名称排列
+// 站点的 Category 应已替换为分类名称；分类表中没有的分类按名称排在之后，没有分类的站点放在最后的「其他」中
+// 每个分类内推荐站点在前，其余按评分从高到低排列，评分相同时保持原有顺序
+func NewAwesomeList(title, description string, sites []models.Site, categories []models.Category, now time.Time) AwesomeList {
+	if title == "" {
+		title = DefaultAwesomeTitle
+	}
+	list := AwesomeList{Title: title, Description: description, GeneratedAt: now, Total: len(sites)}
+
+	var names []string
+	byCategory := make(map[string][]models.Site)
+	for _, site := range sites {
+		category := strings.TrimSpace(site.Category)
+		if _, ok := byCategory[category]; !ok && category != "" {
+			names = append(names, category)
+		}
+		byCategory[category] = append(byCategory[category], site)
+	}
+	rank := categoryRank(categories)
+	sort.SliceStable(names, func(i, j int) bool {
+		ri, oki := rank[names[i]]
+		rj, okj := rank[names[j]]
+		if oki != okj {
+			return oki
+		}
+		if oki {
+			return ri < rj
+		}
+		return names[i] < names[j]
+	})
+	if len(byCategory[""]) > 0 {
+		names = append(names, "")
+	}
+
+	// 内置模板中的标题和「目录」也占用锚点
+	anchors := make(map[string]int)
+	githubAnchor(title, anchors)
+	githubAnchor("目录", anchors)
+	for _, name := range names {
+		grouped := byCategory[name]
+		sort.SliceStable(grouped, func(i, j int) bool {
+			if grouped[i].Featured != grouped[j].Featured {
+				return grouped[i].Featured
+			}
+			return grouped[i].Rating > grouped[j].Rating
+		})
+		if name == "" {
+			name = awesomeUncategorized
+		}
+		list.Categories = append(list.Categories, AwesomeCategory{
+			Name:   name,
+			Anchor: githubAnchor(name, anchors),
+			Sites:  grouped,
+		})
+	}
+	return list
+}
+
+// categoryRank 返回分类名称在分类树中的位置，先父后子，同级按排序和名称排列，与首页的分类导航一致
+// 上级分类不存在的分类作为顶级分类
+func categoryRank(categories []models.Category) map[string]int {
+	slugs := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		slugs[category.Slug] = true
+	}
+	children := make(map[string][]models.Category)
+	for _, category := range categories {
+		parent := category.Parent
+		if !slugs[parent] || parent == category.Slug {
+			parent = ""
+		}
+		children[parent] = append(children[parent], category)
+	}
+
+	rank := make(map[string]int, len(categories))
+	visited := make(map[string]bool, len(categories))
+	var walk func(parent string)
+	walk = func(parent string) {
+		nodes := children[parent]
+		sort.SliceStable(nodes, func(i, j int) bool {
+			if nodes[i].Order != nodes[j].Order {
+				return nodes[i].Order < nodes[j].Order
+			}
+			return nodes[i].Name < nodes[j].Name
+		})
+		for _, node := range nodes {
+			if visited[node.Slug] {
+				continue
+			}
+			visited[node.Slug] = true
+			if _, ok := rank[node.Name]; !ok {
+				rank[node.Name] = len(rank)
+			}
+			walk(node.Slug)
+		}
+	}
+	walk("")
+	return rank
+}
+
+// ParseAwesomeTemplate 读取自定义的 awesome 列表模板（Go text/template 语法），path 为空时使用内置模板
+// 模板中可以使用 md 函数转义链接文字中的 Markdown 特殊字符，join 函数连接字符串列表
+func ParseAwesomeTemplate(path string) (*template.Template, error) {
+	text := DefaultAwesomeTemplate
+	name := "awesome"
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取 awesome 列表模板失败: %w", err)
+		}
+		text = string(data)
+		name = path
+	}
+
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"md":   escapeMarkdown,
+		"join": strings.Join,
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("解析 awesome 列表模板失败: %w", err)
+	}
+	return tmpl, nil
+}
+
+// WriteAwesome 用模板渲染 awesome 列表
+func WriteAwesome(w io.Writer, tmpl *template.Template, list AwesomeList) error {
+	if err := tmpl.Execute(w, list); err != nil {
+		return fmt.Errorf("生成 awesome 列表失败: %w", err)
+	}
+	return nil
+}
+
+// githubAnchor 按 GitHub 的规则生成标题锚点：转为小写，去掉标点，空格换成连字符，重复的锚点依次加 -1、-2 后缀
+func githubAnchor(heading string, seen map[string]int) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	anchor := b.String()
+	n := seen[anchor]
+	seen[anchor] = n + 1
+	if n > 0 {
+		anchor += "-" + strconv.Itoa(n)
+	}
+	return anchor
+}
+
+// markdownReplacer 转义会破坏列表项和链接的字符，并把换行合并为空格
+var markdownReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	"[", `\[`,
+	"]", `\]`,
+	"|", `\|`,
+	"`", "\\`",
+	"\r\n", " ",
+	"\n", " ",
+)
+
+// escapeMarkdown 转义 Markdown 特殊字符，供模板中的 md 函数使用
+func escapeMarkdown(s string) string {
+	return markdownReplacer.Replace(s)
+}
@@ -0,0 +1,198 @@
+// Package exchange 与浏览器、其它导航项目等外部工具交换站点数据
+package exchange
+
+import (
+	"ai-navigator/models"
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	xhtml "golang.org/x/net/html"
+)
+
+// Bookmark 书签文件中的一个链接
+type Bookmark struct {
+	Title       string
+	URL         string
+	Description string
+	// Folders 从外到内的文件夹路径，不含书签栏等浏览器内置的根文件夹
+	Folders []string
+	AddDate time.Time
+}
+
+// Folder 书签所在的最内层文件夹，不在任何文件夹中时为空
+func (b Bookmark) Folder() string {
+	if len(b.Folders) == 0 {
+		return ""
+	}
+	return b.Folders[len(b.Folders)-1]
+}
+
+// ParseBookmarks 解析 Chrome、Firefox、Edge 等浏览器导出的 Netscape 书签文件
+// 只保留 http/https 链接，javascript:、place: 等链接会被跳过
+func ParseBookmarks(r io.Reader) ([]Bookmark, error) {
+	z := xhtml.NewTokenizer(r)
+
+	var (
+		bookmarks []Bookmark
+		// folders 当前所在的文件夹，空字符串表示浏览器内置的根文件夹
+		folders []string
+		// folder 刚读到的 H3 文件夹名，在接下来的 DL 开始时入栈
+		folder        string
+		inFolderTitle bool
+		inLink        bool
+		// description 正在读取描述的书签下标，-1 表示不在 DD 中
+		description = -1
+	)
+
+	for {
+		switch z.Next() {
+		case xhtml.ErrorToken:
+			if z.Err() != io.EOF {
+				return nil, fmt.Errorf("解析书签文件失败: %w", z.Err())
+			}
+			for i := range bookmarks {
+				bookmarks[i].Title = strings.TrimSpace(bookmarks[i].Title)
+				bookmarks[i].Description = strings.TrimSpace(bookmarks[i].Description)
+			}
+			return bookmarks, nil
+
+		case xhtml.StartTagToken:
+			name, _ := z.TagName()
+			attrs := tokenAttrs(z)
+			switch string(name) {
+			case "h3":
+				folder = ""
+				// 书签栏、其他书签等内置文件夹不作为分类
+				inFolderTitle = attrs["personal_toolbar_folder"] != "true" && attrs["unfiled_bookmarks_folder"] != "true"
+			case "dl":
+				folders = append(folders, strings.TrimSpace(folder))
+				folder = ""
+			case "a":
+				href := strings.TrimSpace(attrs["href"])
+				if !strings.HasPrefix(href, "http://") && !strings.HasPrefix(href, "https://") {
+					continue
+				}
+				bookmarks = append(bookmarks, Bookmark{
+					URL:     href,
+					Folders: folderPath(folders),
+					AddDate: parseUnix(attrs["add_date"]),
+				})
+				inLink = true
+			case "dd":
+				description = len(bookmarks) - 1
+			case "dt":
+				description = -1
+			}
+
+		case xhtml.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "h3":
+				inFolderTitle = false
+			case "a":
+				inLink = false
+			case "dl":
+				if len(folders) > 0 {
+					folders = folders[:len(folders)-1]
+				}
+				description = -1
+			}
+
+		case xhtml.TextToken:
+			text := string(z.Text())
+			switch {
+			case inFolderTitle:
+				folder += text
+			case inLink:
+				bookmarks[len(bookmarks)-1].Title += text
+			case description >= 0:
+				bookmarks[description].Description += text
+			}
+		}
+	}
+}
+
+// tokenAttrs 读取当前标签的全部属性，属性名统一为小写
+func tokenAttrs(z *xhtml.Tokenizer) map[string]string {
+	attrs := make(map[string]string)
+	for {
+		key, val, more := z.TagAttr()
+		if len(key) > 0 {
+			attrs[strings.ToLower(string(key))] = string(val)
+		}
+		if !more {
+			return attrs
+		}
+	}
+}
+
+// folderPath 去掉浏览器内置的根文件夹后的文件夹路径
+func folderPath(stack []string) []string {
+	var path []string
+	for _, name := range stack {
+		if name != "" {
+			path = append(path, name)
+		}
+	}
+	return path
+}
+
+func parseUnix(s string) time.Time {
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || sec <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+// WriteBookmarks 将站点按分类写成 Netscape 书签文件，可导入 Chrome、Firefox、Edge
+// 全部站点放在名为 title 的文件夹中，每个分类一个子文件夹，未分类的站点直接放在该文件夹下
+func WriteBookmarks(w io.Writer, title string, sites []models.Site) error {
+	bw := bufio.NewWriter(w)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	var categories []string
+	byCategory := make(map[string][]models.Site)
+	for _, site := range sites {
+		if _, ok := byCategory[site.Category]; !ok && site.Category != "" {
+			categories = append(categories, site.Category)
+		}
+		byCategory[site.Category] = append(byCategory[site.Category], site)
+	}
+
+	writeSites := func(indent string, sites []models.Site) {
+		for _, site := range sites {
+			fmt.Fprintf(bw, "%s<DT><A HREF=\"%s\" ADD_DATE=\"%s\">%s</A>\n", indent, html.EscapeString(site.URL), now, html.EscapeString(site.Name))
+			if site.Description != "" {
+				fmt.Fprintf(bw, "%s<DD>%s\n", indent, html.EscapeString(site.Description))
+			}
+		}
+	}
+
+	fmt.Fprint(bw, `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<!-- This is an automatically generated file.
+     It will be read and overwritten.
+     DO NOT EDIT! -->
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+`)
+	fmt.Fprintf(bw, "    <DT><H3 ADD_DATE=\"%s\" LAST_MODIFIED=\"%s\">%s</H3>\n", now, now, html.EscapeString(title))
+	fmt.Fprint(bw, "    <DL><p>\n")
+	for _, category := range categories {
+		fmt.Fprintf(bw, "        <DT><H3 ADD_DATE=\"%s\" LAST_MODIFIED=\"%s\">%s</H3>\n", now, now, html.EscapeString(category))
+		fmt.Fprint(bw, "        <DL><p>\n")
+		writeSites("            ", byCategory[category])
+		fmt.Fprint(bw, "        </DL><p>\n")
+	}
+	writeSites("        ", byCategory[""])
+	fmt.Fprint(bw, "    </DL><p>\n</DL><p>\n")
+
+	return bw.Flush()
+}
@@ -0,0 +1,149 @@
+package exchange
+
+import (
+	"ai-navigator/models"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// webStackLogoDir 导入时 WebStack 中只写了文件名的 logo 放在该目录下，导出时去掉该前缀
+const webStackLogoDir = "/static/img/"
+
+// webStackIcon 导出时每个分类使用的 Font Awesome 图标
+const webStackIcon = "fas fa-tag"
+
+// WebStackTaxonomy WebStack 数据文件（如 Hugo 主题的 data/webstack.yml）中的一个分类
+// 分类下直接是 links，或者是按 term 分组的 list，两者只会出现一种
+type WebStackTaxonomy struct {
+	Taxonomy string         `yaml:"taxonomy"`
+	Icon     string         `yaml:"icon,omitempty"`
+	Links    []WebStackLink `yaml:"links,omitempty"`
+	List     []WebStackTerm `yaml:"list,omitempty"`
+}
+
+// WebStackTerm 分类下的子分组
+type WebStackTerm struct {
+	Term  string         `yaml:"term"`
+	Links []WebStackLink `yaml:"links"`
+}
+
+// WebStackLink WebStack 中的一个链接
+type WebStackLink struct {
+	Title       string `yaml:"title"`
+	Logo        string `yaml:"logo,omitempty"`
+	URL         string `yaml:"url"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// ParseWebStack 解析 WebStack 数据文件，taxonomy 作为分类，list 中的 term 作为站点的标签
+// 只写了文件名的 logo 视为放在 /static/img/ 下，需要把图片复制到 static/img 目录
+func ParseWebStack(r io.Reader) ([]models.Site, error) {
+	var taxonomies []WebStackTaxonomy
+	if err := yaml.NewDecoder(r).Decode(&taxonomies); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("解析 WebStack 数据失败: %w", err)
+	}
+
+	var sites []models.Site
+	add := func(category, term string, links []WebStackLink) {
+		for _, link := range links {
+			site := models.Site{
+				Name:        strings.TrimSpace(link.Title),
+				URL:         strings.TrimSpace(link.URL),
+				Description: strings.TrimSpace(link.Description),
+				Logo:        webStackLogoPath(strings.TrimSpace(link.Logo)),
+				Category:    category,
+				Tags:        []string{},
+			}
+			if term != "" {
+				site.Tags = append(site.Tags, term)
+			}
+			sites = append(sites, site)
+		}
+	}
+	for _, t := range taxonomies {
+		category := strings.TrimSpace(t.Taxonomy)
+		add(category, "", t.Links)
+		for _, term := range t.List {
+			add(category, strings.TrimSpace(term.Term), term.Links)
+		}
+	}
+	return sites, nil
+}
+
+// webStackOtherTerm 分类按 term 分组时，没有标签的站点所在的分组
+const webStackOtherTerm = "其他"
+
+// WriteWebStack 将站点按分类写成 WebStack 数据文件，分类按站点中首次出现的顺序排列，没有分类的站点放在「未分类」中
+// 导入时 term 成为站点的第一个标签，导出时按第一个标签重建 term 分组：分类中有站点带标签时写成 list，
+// 同一分类中没有标签的站点放在「其他」分组；分类中的站点都没有标签时直接列出 links
+func WriteWebStack(w io.Writer, sites []models.Site) error {
+	type group struct {
+		taxonomy string
+		terms    []WebStackTerm
+		index    map[string]int
+		hasTerm  bool
+	}
+	var groups []*group
+	byCategory := make(map[string]*group)
+	for _, site := range sites {
+		category := site.Category
+		if category == "" {
+			category = "未分类"
+		}
+		g, ok := byCategory[category]
+		if !ok {
+			g = &group{taxonomy: category, index: make(map[string]int)}
+			byCategory[category] = g
+			groups = append(groups, g)
+		}
+
+		term := webStackOtherTerm
+		if len(site.Tags) > 0 {
+			term = site.Tags[0]
+			g.hasTerm = true
+		}
+		i, ok := g.index[term]
+		if !ok {
+			i = len(g.terms)
+			g.index[term] = i
+			g.terms = append(g.terms, WebStackTerm{Term: term})
+		}
+		g.terms[i].Links = append(g.terms[i].Links, WebStackLink{
+			Title:       site.Name,
+			Logo:        strings.TrimPrefix(site.Logo, webStackLogoDir),
+			URL:         site.URL,
+			Description: site.Description,
+		})
+	}
+
+	taxonomies := make([]WebStackTaxonomy, len(groups))
+	for i, g := range groups {
+		taxonomies[i] = WebStackTaxonomy{Taxonomy: g.taxonomy, Icon: webStackIcon}
+		if g.hasTerm {
+			taxonomies[i].List = g.terms
+		} else {
+			taxonomies[i].Links = g.terms[0].Links
+		}
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(taxonomies); err != nil {
+		return fmt.Errorf("序列化 WebStack 数据失败: %w", err)
+	}
+	return enc.Close()
+}
+
+// webStackLogoPath 将 WebStack 中的 logo 转换为本站使用的路径，完整地址和绝对路径保持不变
+func webStackLogoPath(logo string) string {
+	if logo == "" || strings.HasPrefix(logo, "/") || strings.Contains(logo, "://") {
+		return logo
+	}
+	return webStackLogoDir + logo
+}
@@ -3,10 +3,15 @@ package handlers
 import (
 	"ai-navigator/config"
 	"ai-navigator/models"
+	"ai-navigator/store"
 	"ai-navigator/utils"
+	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
@@ -31,6 +36,7 @@ func AdminLoginPostHandler(c *gin.Context) {
 	if username == config.AppConfig.Admin.Username && password == config.AppConfig.Admin.Password {
 		session := sessions.Default(c)
 		session.Set("admin_logged_in", true)
+		session.Set("admin_user", username)
 		session.Save()
 		c.Redirect(http.StatusFound, "/admin")
 	} else {
@@ -43,6 +49,7 @@ func AdminLoginPostHandler(c *gin.Context) {
 func AdminLogoutHandler(c *gin.Context) {
 	session := sessions.Default(c)
 	session.Set("admin_logged_in", false)
+	session.Delete("admin_user")
 	session.Save()
 	c.Redirect(http.StatusFound, "/admin/login")
 }
@@ -52,172 +59,320 @@ func CaptchaHandler(c *gin.Context) {
 }
 
 func AdminIndexHandler(c *gin.Context) {
-	sitesLock.RLock()
-	defer sitesLock.RUnlock()
+	snapshot := currentCatalog()
+
+	status := getLoadStatus()
+	data := gin.H{
+		"siteCount":      len(snapshot.Sites),
+		"catalogVersion": snapshot.Version,
+		"loadStatus":     status,
+		"isAdmin":        true,
+	}
 
-	c.HTML(http.StatusOK, "admin-index.html", gin.H{
-		"siteCount": len(sites),
-		"isAdmin":   true,
-	})
+	if lister, ok := siteStore.(store.PackLister); ok {
+		if packs, err := lister.Packs(); err == nil {
+			data["packs"] = packs
+		}
+	}
+
+	data["duplicateCount"] = len(snapshot.Duplicates())
+
+	if drift, ok := siteStore.(store.Drift); ok {
+		if conflicts, err := drift.Conflicts(); err == nil {
+			data["driftSupported"] = true
+			data["conflictCount"] = len(conflicts)
+		}
+	}
+
+	if reporter, ok := siteStore.(store.RemoteReporter); ok {
+		if remote, ok := reporter.RemoteStatus(); ok {
+			data["remote"] = remote
+		}
+	}
+
+	var validationErrs store.ValidationErrors
+	if errors.As(status.Err, &validationErrs) {
+		data["validationErrors"] = validationErrs
+	} else if status.Err != nil {
+		data["loadError"] = status.Err.Error()
+	}
+
+	c.HTML(http.StatusOK, "admin-index.html", data)
 }
 
 func AdminSitesHandler(c *gin.Context) {
-	displaySites := getDisplaySites()
-
 	c.HTML(http.StatusOK, "admin-sites.html", gin.H{
-		"sites":   displaySites,
+		"sites":   currentCatalog().Display,
 		"isAdmin": true,
 	})
 }
 
 func AdminAddSiteHandler(c *gin.Context) {
 	c.HTML(http.StatusOK, "admin-add-site.html", gin.H{
-		"isAdmin": true,
+		"site":            models.Site{},
+		"categoryOptions": currentCatalog().Categories.categoryOptions(""),
+		"tagSuggestions":  tagSuggestions(),
+		"pricingForm":     newPricingForm(nil),
+		"isAdmin":         true,
 	})
 }
 
 func AdminAddSitePostHandler(c *gin.Context) {
-	var site models.Site
-
-	site.Name = c.PostForm("Name")
-	site.URL = c.PostForm("URL")
-	site.Description = c.PostForm("Description")
-	site.Logo = c.PostForm("Logo")
-	site.Category = c.PostForm("Category")
-
-	ratingStr := c.PostForm("Rating")
-	if ratingStr != "" {
-		if rating, err := strconv.ParseFloat(ratingStr, 64); err == nil {
-			site.Rating = rating
+	site, err := siteFromForm(c)
+	site.Pricing = site.Pricing.Track(nil, today())
+	if err == nil && c.PostForm("ConfirmDuplicate") == "" {
+		if duplicates := findDuplicatesOf(site); len(duplicates) > 0 {
+			c.HTML(http.StatusOK, "admin-add-site.html", gin.H{
+				"duplicates":      duplicates,
+				"site":            site,
+				"tagsString":      strings.Join(site.Tags, ", "),
+				"categoryOptions": currentCatalog().Categories.categoryOptions(site.Category),
+				"tagSuggestions":  tagSuggestions(),
+				"pricingForm":     newPricingForm(site.Pricing),
+				"isAdmin":         true,
+			})
+			return
 		}
 	}
 
-	site.Featured = c.PostForm("Featured") == "on"
-
-	tagsStr := c.PostForm("Tags")
-	if tagsStr != "" {
-		site.Tags = strings.Split(strings.ReplaceAll(tagsStr, " ", ""), ",")
-	} else {
-		site.Tags = []string{}
+	if err == nil {
+		site, err = siteStore.Create(site)
+	}
+	if msg, ok := validationMessage(err); ok {
+		c.HTML(http.StatusOK, "admin-add-site.html", gin.H{
+			"error":           msg,
+			"site":            site,
+			"tagsString":      strings.Join(site.Tags, ", "),
+			"categoryOptions": currentCatalog().Categories.categoryOptions(site.Category),
+			"tagSuggestions":  tagSuggestions(),
+			"pricingForm":     newPricingForm(site.Pricing),
+			"isAdmin":         true,
+		})
+		return
+	}
+	if err != nil {
+		renderStoreError(c, err)
+		return
 	}
 
-	sitesLock.Lock()
-	sites = append(sites, site)
-	sitesLock.Unlock()
-
-	saveSites()
-	loadSites()
+	recordRevision(c, models.RevisionCreate, nil, &site)
+	sitesSaved()
 
 	c.Redirect(http.StatusFound, "/admin/sites")
 }
 
 func AdminEditSiteHandler(c *gin.Context) {
 	id := c.Param("id")
-	siteIndex := -1
 
-	sitesLock.RLock()
-	for i, s := range sites {
-		if s.Name == id {
-			siteIndex = i
-			break
+	site, err := siteStore.Get(id)
+	if err != nil {
+		renderStoreError(c, err)
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin-edit-site.html", editFormData(site, site, site.Version(), siteJSON(site)))
+}
+
+func AdminEditSitePostHandler(c *gin.Context) {
+	id := c.Param("id")
+
+	current, err := siteStore.Get(id)
+	if err != nil {
+		renderStoreError(c, err)
+		return
+	}
+
+	site, err := siteFromForm(c)
+	site.ID = current.ID
+	site.Visits = current.Visits
+	site.CreatedAt = current.CreatedAt
+	site.Pricing = site.Pricing.Track(current.Pricing, today())
+
+	version, original := c.PostForm("Version"), c.PostForm("Original")
+
+	// 只在名称或网址变化时提示重复，避免每次编辑已知的相似站点都要确认
+	changed := site.Name != current.Name || utils.NormalizeURL(site.URL) != utils.NormalizeURL(current.URL)
+	if err == nil && changed && c.PostForm("ConfirmDuplicate") == "" {
+		if duplicates := findDuplicatesOf(site); len(duplicates) > 0 {
+			data := editFormData(site, current, version, original)
+			data["duplicates"] = duplicates
+			c.HTML(http.StatusOK, "admin-edit-site.html", data)
+			return
 		}
 	}
-	defer sitesLock.RUnlock()
 
-	if siteIndex == -1 {
-		c.HTML(http.StatusNotFound, "error.html", gin.H{
-			"error": "站点不存在",
-		})
+	var saved models.Site
+	if err == nil {
+		saved, err = saveEdit(id, version, site)
+	}
+	if errors.Is(err, store.ErrStale) {
+		renderEditConflict(c, original, saved, site)
+		return
+	}
+	if msg, ok := validationMessage(err); ok {
+		data := editFormData(site, current, version, original)
+		data["error"] = msg
+		c.HTML(http.StatusOK, "admin-edit-site.html", data)
+		return
+	}
+	if err != nil {
+		renderStoreError(c, err)
 		return
 	}
 
-	tagsString := ""
-	if len(sites[siteIndex].Tags) > 0 {
-		tagsString = strings.Join(sites[siteIndex].Tags, ", ")
+	recordRevision(c, models.RevisionUpdate, &current, &saved)
+	sitesSaved()
+
+	c.Redirect(http.StatusFound, "/admin/sites")
+}
+
+// editFormData 编辑页面的数据，site 为表单中显示的内容，current 为已保存的站点
+// version 和 original 是开始编辑时站点的版本和内容，重新显示表单时原样保留，保存时据此发现他人的修改
+func editFormData(site, current models.Site, version, original string) gin.H {
+	return gin.H{
+		"site":            site,
+		"tagsString":      strings.Join(site.Tags, ", "),
+		"categoryOptions": currentCatalog().Categories.categoryOptions(site.Category),
+		"tagSuggestions":  tagSuggestions(),
+		"pricingForm":     newPricingForm(site.Pricing),
+		"overrides":       siteOverrides(current),
+		"conflicts":       siteConflictFields(current.ID),
+		"version":         version,
+		"original":        original,
+		"isAdmin":         true,
 	}
+}
 
-	c.HTML(http.StatusOK, "admin-edit-site.html", gin.H{
-		"site":       sites[siteIndex],
-		"tagsString": tagsString,
-		"isAdmin":    true,
-	})
+// saveEdit 保存编辑后的站点，表单带有版本且存储支持时只在版本一致时写入
+// 版本不一致时返回 store.ErrStale 和站点当前的内容
+func saveEdit(id, version string, site models.Site) (models.Site, error) {
+	if updater, ok := siteStore.(store.VersionedUpdater); ok && version != "" {
+		return updater.UpdateVersion(id, version, site)
+	}
+	return site, siteStore.Update(id, site)
 }
 
-func AdminEditSitePostHandler(c *gin.Context) {
-	id := c.Param("id")
-	siteIndex := -1
+// renderEditConflict 提交的修改基于过期的版本时，显示他人在此期间的修改和本次提交的修改
+// original 为开始编辑时的站点内容，无法解析时只比较站点当前的内容和本次提交的内容
+func renderEditConflict(c *gin.Context, original string, latest, mine models.Site) {
+	base := &latest
+	var opened models.Site
+	if err := json.Unmarshal([]byte(original), &opened); err == nil && opened.ID == latest.ID {
+		base = &opened
+	}
 
-	sitesLock.Lock()
-	for i, s := range sites {
-		if s.Name == id {
-			siteIndex = i
-			break
+	data := gin.H{
+		"site":         latest,
+		"mine":         mine,
+		"mineTags":     strings.Join(mine.Tags, ", "),
+		"theirChanges": models.DiffSites(base, &latest),
+		"myChanges":    models.DiffSites(base, &mine),
+		"version":      latest.Version(),
+		"original":     siteJSON(latest),
+		"isAdmin":      true,
+	}
+	if revLog, ok := siteStore.(store.RevisionLog); ok {
+		if revisions, err := revLog.ListRevisions(latest.ID); err == nil && len(revisions) > 0 {
+			data["lastRevision"] = revisions[0]
 		}
 	}
 
-	if siteIndex == -1 {
-		sitesLock.Unlock()
-		c.HTML(http.StatusNotFound, "error.html", gin.H{
-			"error": "站点不存在",
-		})
+	c.HTML(http.StatusConflict, "admin-edit-conflict.html", data)
+}
+
+// siteJSON 将站点序列化为 JSON 文本，用于在表单中保存开始编辑时的内容
+func siteJSON(site models.Site) string {
+	data, _ := json.Marshal(site)
+	return string(data)
+}
+
+func AdminDeleteSiteHandler(c *gin.Context) {
+	id := c.Param("id")
+
+	current, err := siteStore.Get(id)
+	if err != nil {
+		renderStoreError(c, err)
 		return
 	}
 
-	sites[siteIndex].Name = c.PostForm("Name")
-	sites[siteIndex].URL = c.PostForm("URL")
-	sites[siteIndex].Description = c.PostForm("Description")
-	sites[siteIndex].Logo = c.PostForm("Logo")
-	sites[siteIndex].Category = c.PostForm("Category")
+	if err := siteStore.Delete(id, currentAdmin(c)); err != nil {
+		renderStoreError(c, err)
+		return
+	}
 
-	ratingStr := c.PostForm("Rating")
-	if ratingStr != "" {
-		if rating, err := strconv.ParseFloat(ratingStr, 64); err == nil {
-			sites[siteIndex].Rating = rating
+	// 修订中记录存储写入的删除时间和操作人，与回收站显示的一致
+	deleted := current
+	deleted.Deleted = true
+	deleted.DeletedAt = time.Now().Format(time.RFC3339)
+	deleted.DeletedBy = currentAdmin(c)
+	if trash, ok := siteStore.(store.Trash); ok {
+		if stored, err := findDeleted(trash, id); err == nil {
+			deleted = stored
 		}
 	}
+	recordRevision(c, models.RevisionDelete, &current, &deleted)
+	sitesSaved()
 
-	sites[siteIndex].Featured = c.PostForm("Featured") == "on"
+	c.Redirect(http.StatusFound, "/admin/sites")
+}
 
-	tagsStr := c.PostForm("Tags")
-	if tagsStr != "" {
-		sites[siteIndex].Tags = strings.Split(strings.ReplaceAll(tagsStr, " ", ""), ",")
-	} else {
-		sites[siteIndex].Tags = []string{}
+// siteFromForm 从后台表单读取站点字段，字段无法解析时同时返回校验错误
+func siteFromForm(c *gin.Context) (models.Site, error) {
+	var site models.Site
+
+	site.Name = c.PostForm("Name")
+	site.URL = utils.CleanURL(c.PostForm("URL"))
+	site.Description = c.PostForm("Description")
+	site.Logo = c.PostForm("Logo")
+	site.Category = c.PostForm("Category")
+
+	ratingStr := c.PostForm("Rating")
+	if ratingStr != "" {
+		if rating, err := strconv.ParseFloat(ratingStr, 64); err == nil {
+			site.Rating = rating
+		}
 	}
 
-	sitesLock.Unlock()
+	site.Featured = c.PostForm("Featured") == "on"
+	pricing, err := pricingFromForm(c)
+	site.Pricing = pricing
 
-	saveSites()
-	loadSites()
+	site.Tags = currentCatalog().Tags.normalize(utils.SplitTags(c.PostForm("Tags")))
 
-	c.Redirect(http.StatusFound, "/admin/sites")
+	return site, err
 }
 
-func AdminDeleteSiteHandler(c *gin.Context) {
-	id := c.Param("id")
-	siteIndex := -1
+// validationMessage 将校验错误转换为表单上展示的提示
+func validationMessage(err error) (string, bool) {
+	var errs store.ValidationErrors
+	if !errors.As(err, &errs) {
+		return "", false
+	}
 
-	sitesLock.Lock()
-	for i, s := range sites {
-		if s.Name == id {
-			siteIndex = i
-			break
-		}
+	reasons := make([]string, len(errs))
+	for i, e := range errs {
+		reasons[i] = e.Reason
 	}
+	return "保存失败：" + strings.Join(reasons, "；"), true
+}
 
-	if siteIndex == -1 {
-		sitesLock.Unlock()
+// renderStoreError 将存储层错误渲染为错误页面
+func renderStoreError(c *gin.Context, err error) {
+	if errors.Is(err, store.ErrNotFound) {
 		c.HTML(http.StatusNotFound, "error.html", gin.H{
 			"error": "站点不存在",
 		})
 		return
 	}
+	if errors.Is(err, store.ErrExists) {
+		c.HTML(http.StatusConflict, "error.html", gin.H{
+			"error": "站点已存在",
+		})
+		return
+	}
 
-	sites[siteIndex].Deleted = true
-	sitesLock.Unlock()
-
-	saveSites()
-	loadSites()
-
-	c.Redirect(http.StatusFound, "/admin/sites")
+	log.Printf("站点数据操作失败: %v", err)
+	c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+		"error": "保存站点数据失败",
+	})
 }
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"ai-navigator/store"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func AdminBackupsHandler(c *gin.Context) {
+	backupper, ok := siteStore.(store.Backupper)
+	if !ok {
+		c.HTML(http.StatusOK, "admin-backups.html", gin.H{
+			"unsupported": true,
+			"isAdmin":     true,
+		})
+		return
+	}
+
+	backups, err := backupper.ListBackups()
+	if err != nil {
+		log.Printf("读取备份列表失败: %v", err)
+	}
+
+	c.HTML(http.StatusOK, "admin-backups.html", gin.H{
+		"backups":  backups,
+		"restored": c.Query("restored"),
+		"isAdmin":  true,
+	})
+}
+
+func AdminRestoreBackupHandler(c *gin.Context) {
+	backupper, ok := siteStore.(store.Backupper)
+	if !ok {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": "当前存储不支持备份恢复",
+		})
+		return
+	}
+
+	name := c.Param("name")
+	if err := backupper.RestoreBackup(name); err != nil {
+		if errors.Is(err, store.ErrInvalidBackup) {
+			c.HTML(http.StatusBadRequest, "error.html", gin.H{
+				"error": "备份文件不存在或已损坏",
+			})
+			return
+		}
+		renderStoreError(c, err)
+		return
+	}
+
+	log.Printf("已从备份 %s 恢复 custom.json", name)
+	commitChange(c, "从备份 "+name+" 恢复 custom.json")
+	loadSites()
+
+	c.Redirect(http.StatusFound, "/admin/backups?restored="+name)
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"ai-navigator/models"
+	"ai-navigator/utils"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// catalog 某次加载得到的站点目录快照，发布后不再修改
+// 处理器通过 currentCatalog 读取快照，无需加锁，同一请求内看到的站点、展示数据、分类和索引始终来自同一版本
+type catalog struct {
+	// Version 快照的版本号，每次成功加载加一，从 1 开始；0 表示尚未加载成功
+	Version  uint64
+	LoadedAt time.Time
+
+	Sites   []models.Site
+	Display []models.SiteDisplay
+	// Categories 分类表和站点中出现的全部分类
+	Categories *categoryTree
+	// Tags 标签注册表，站点的标签在构建快照时已按注册表换成规范名称
+	Tags *tagRegistry
+
+	byID  map[string]int
+	byURL map[string]int
+	// derived 与 Sites 一一对应的预计算数据，下一次加载时内容哈希没有变化的站点直接沿用
+	derived []siteDerived
+
+	// duplicates 可能重复的站点对，站点很多时查找较慢，第一次用到时才计算，不影响快照的发布
+	duplicatesOnce sync.Once
+	duplicates     []duplicatePair
+
+	// tagUsage 每个标签的站点数量，只有标签管理页面用到，第一次用到时才统计
+	tagUsageOnce sync.Once
+	tagUsage     []tagUsage
+}
+
+// siteDerived 由站点内容计算出的展示和比较数据
+type siteDerived struct {
+	hash     uint64
+	color    string
+	initials string
+	// urlKey 用于按网址查找的键，hostPath 和 name 用于查找重复站点
+	urlKey   string
+	hostPath string
+	name     utils.NameKeys
+}
+
+func deriveSite(site models.Site, hash uint64) siteDerived {
+	return siteDerived{
+		hash:     hash,
+		color:    utils.GenerateColorFromName(site.Name),
+		initials: utils.GetInitialsFromName(site.Name),
+		urlKey:   utils.NormalizeURL(site.URL),
+		hostPath: utils.URLHostPath(site.URL),
+		name:     utils.NewNameKeys(site.Name),
+	}
+}
+
+// catalogPtr 当前发布的快照，只由 loadSites 替换
+var catalogPtr atomic.Pointer[catalog]
+
+func init() {
+	catalogPtr.Store(newCatalog(0, nil, nil, nil, nil))
+}
+
+// currentCatalog 返回当前发布的快照，调用方不得修改其中的数据
+func currentCatalog() *catalog {
+	return catalogPtr.Load()
+}
+
+// newCatalog 根据站点列表、分类表和标签注册表构建快照，预先计算展示数据、分类树和索引
+// 站点的标签按注册表换成规范名称，sites 中需要修改的站点会被替换为修改后的副本
+// prev 为上一份快照，其中内容哈希相同的站点沿用已经计算好的数据，只重新计算新增和修改过的站点
+func newCatalog(version uint64, sites []models.Site, categories []models.Category, tags []models.Tag, prev *catalog) *catalog {
+	registry := newTagRegistry(tags)
+	for i := range sites {
+		sites[i].Tags = registry.normalize(sites[i].Tags)
+	}
+
+	c := &catalog{
+		Version:    version,
+		LoadedAt:   time.Now(),
+		Sites:      sites,
+		Display:    make([]models.SiteDisplay, len(sites)),
+		Categories: buildCategoryTree(categories, sites),
+		Tags:       registry,
+		byID:       make(map[string]int, len(sites)),
+		byURL:      make(map[string]int, len(sites)),
+		derived:    make([]siteDerived, len(sites)),
+	}
+	for i, site := range sites {
+		hash := site.ContentHash()
+		d, ok := prev.derivedOf(site.ID)
+		if !ok || d.hash != hash {
+			d = deriveSite(site, hash)
+		}
+		c.derived[i] = d
+		c.Display[i] = models.SiteDisplay{
+			Site:      site,
+			Color:     d.color,
+			Initials:  d.initials,
+			TagBadges: registry.badges(site.Tags),
+		}
+		c.byID[site.ID] = i
+		c.byURL[d.urlKey] = i
+	}
+	return c
+}
+
+// Duplicates 返回快照中可能重复的站点对，第一次调用时计算，之后直接返回结果
+func (c *catalog) Duplicates() []duplicatePair {
+	c.duplicatesOnce.Do(func() {
+		c.duplicates = findDuplicatePairs(c.Sites, c.derived)
+	})
+	return c.duplicates
+}
+
+// TagUsage 返回注册表和站点中全部标签的使用次数，第一次调用时统计，之后直接返回结果
+func (c *catalog) TagUsage() []tagUsage {
+	c.tagUsageOnce.Do(func() {
+		c.tagUsage = countTags(c.Tags, c.Sites)
+	})
+	return c.tagUsage
+}
+
+// derivedOf 返回快照中站点的预计算数据，c 为 nil 时视为空快照
+func (c *catalog) derivedOf(id string) (siteDerived, bool) {
+	if c == nil {
+		return siteDerived{}, false
+	}
+	i, ok := c.byID[id]
+	if !ok {
+		return siteDerived{}, false
+	}
+	return c.derived[i], true
+}
+
+// site 根据 ID 查找站点
+func (c *catalog) site(id string) (models.Site, bool) {
+	i, ok := c.byID[id]
+	if !ok {
+		return models.Site{}, false
+	}
+	return c.Sites[i], true
+}
+
+// siteByURL 查找网址相同（按 utils.NormalizeURL 比较）的站点
+func (c *catalog) siteByURL(rawURL string) (models.Site, bool) {
+	i, ok := c.byURL[utils.NormalizeURL(rawURL)]
+	if !ok {
+		return models.Site{}, false
+	}
+	return c.Sites[i], true
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"ai-navigator/models"
+	"ai-navigator/store"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminCategoriesHandler 分类管理页面：列出分类树和每个分类的站点数量，提供新增和编辑分类的表单
+// 带 edit 参数时编辑对应的分类，带 name 参数时以站点中未定义的分类名称预填新增表单
+func AdminCategoriesHandler(c *gin.Context) {
+	category := models.Category{Name: c.Query("name")}
+	editing := false
+	if slug := c.Query("edit"); slug != "" {
+		node, ok := currentCatalog().Categories.bySlug[slug]
+		if !ok {
+			c.HTML(http.StatusNotFound, "error.html", gin.H{
+				"error": "分类不存在",
+			})
+			return
+		}
+		category, editing = node.Category, true
+	}
+
+	renderCategories(c, http.StatusOK, category, editing, gin.H{
+		"saved":   c.Query("saved"),
+		"deleted": c.Query("deleted"),
+	})
+}
+
+// AdminAddCategoryHandler 新增分类
+func AdminAddCategoryHandler(c *gin.Context) {
+	categoryStore, ok := categoryStoreOrError(c)
+	if !ok {
+		return
+	}
+
+	category := categoryFromForm(c)
+	category.Slug = strings.ToLower(strings.TrimSpace(c.PostForm("Slug")))
+	if err := categoryStore.CreateCategory(category); err != nil {
+		renderCategoryError(c, category, false, err)
+		return
+	}
+
+	commitChange(c, "新增分类 "+category.Name)
+	loadSites()
+	c.Redirect(http.StatusFound, "/admin/categories?saved="+url.QueryEscape(category.Name))
+}
+
+// AdminEditCategoryHandler 保存分类的修改，标识不能修改
+func AdminEditCategoryHandler(c *gin.Context) {
+	categoryStore, ok := categoryStoreOrError(c)
+	if !ok {
+		return
+	}
+
+	category := categoryFromForm(c)
+	category.Slug = c.Param("slug")
+	if err := categoryStore.UpdateCategory(category); err != nil {
+		renderCategoryError(c, category, true, err)
+		return
+	}
+
+	commitChange(c, "编辑分类 "+category.Name)
+	loadSites()
+	c.Redirect(http.StatusFound, "/admin/categories?saved="+url.QueryEscape(category.Name))
+}
+
+// AdminDeleteCategoryHandler 删除分类，还有站点或子分类时不能删除
+func AdminDeleteCategoryHandler(c *gin.Context) {
+	categoryStore, ok := categoryStoreOrError(c)
+	if !ok {
+		return
+	}
+
+	slug := c.Param("slug")
+	node, ok := currentCatalog().Categories.bySlug[slug]
+	if !ok {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "分类不存在",
+		})
+		return
+	}
+	if node.Count > 0 {
+		renderCategories(c, http.StatusConflict, models.Category{}, false, gin.H{
+			"error": fmt.Sprintf("分类「%s」下还有 %d 个站点，请先修改这些站点的分类", node.Name, node.Count),
+		})
+		return
+	}
+
+	if err := categoryStore.DeleteCategory(slug); err != nil {
+		renderCategoryError(c, models.Category{}, false, err)
+		return
+	}
+
+	commitChange(c, "删除分类 "+node.Name)
+	loadSites()
+	c.Redirect(http.StatusFound, "/admin/categories?deleted="+url.QueryEscape(node.Name))
+}
+
+// renderCategories 渲染分类管理页面，category 为表单中显示的分类，editing 表示表单用于编辑已有分类
+func renderCategories(c *gin.Context, status int, category models.Category, editing bool, data gin.H) {
+	tree := currentCatalog().Categories
+	_, supported := siteStore.(store.CategoryStore)
+
+	// 上级分类不能选择自身及其子分类
+	var parents []*categoryNode
+	for _, node := range tree.List {
+		if !node.Defined {
+			continue
+		}
+		if editing && isCategoryWithin(node, category.Slug) {
+			continue
+		}
+		parents = append(parents, node)
+	}
+
+	data["categories"] = tree.List
+	data["parents"] = parents
+	data["category"] = category
+	data["editing"] = editing
+	data["unsupported"] = !supported
+	data["isAdmin"] = true
+	c.HTML(status, "admin-categories.html", data)
+}
+
+// isCategoryWithin 判断 node 是否为标识为 slug 的分类或它的子分类
+func isCategoryWithin(node *categoryNode, slug string) bool {
+	for n := node; n != nil; n = n.parent {
+		if n.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// renderCategoryError 保存分类失败时重新显示表单和错误原因
+func renderCategoryError(c *gin.Context, category models.Category, editing bool, err error) {
+	var msg string
+	switch {
+	case errors.Is(err, store.ErrCategoryExists):
+		msg = "保存失败：标识为 " + category.Slug + " 的分类已存在"
+	case errors.Is(err, store.ErrCategoryNotFound):
+		msg = "分类不存在"
+	case errors.Is(err, store.ErrCategoryHasChildren):
+		msg = "删除失败：分类下还有子分类，请先删除或移动子分类"
+	default:
+		var ok bool
+		if msg, ok = validationMessage(err); !ok {
+			log.Printf("保存分类失败: %v", err)
+			msg = "保存分类数据失败"
+		}
+	}
+	renderCategories(c, http.StatusOK, category, editing, gin.H{"error": msg})
+}
+
+// categoryStoreOrError 返回支持分类管理的存储，不支持时渲染错误页面
+func categoryStoreOrError(c *gin.Context) (store.CategoryStore, bool) {
+	categoryStore, ok := siteStore.(store.CategoryStore)
+	if !ok {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": "当前存储不支持分类管理",
+		})
+	}
+	return categoryStore, ok
+}
+
+// categoryFromForm 从分类表单读取标识以外的字段
+func categoryFromForm(c *gin.Context) models.Category {
+	order, _ := strconv.Atoi(strings.TrimSpace(c.PostForm("Order")))
+	return models.Category{
+		Name:        strings.TrimSpace(c.PostForm("Name")),
+		Icon:        strings.TrimSpace(c.PostForm("Icon")),
+		Description: strings.TrimSpace(c.PostForm("Description")),
+		Parent:      c.PostForm("Parent"),
+		Order:       order,
+	}
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"ai-navigator/models"
+	"sort"
+	"strings"
+)
+
+// categoryNode 分类树中的一个分类
+type categoryNode struct {
+	models.Category
+	// Defined 分类表中有定义；站点中出现但没有定义的分类以站点的 Category 值作为标识和名称
+	Defined bool
+	// Count 本分类及全部子分类下的站点数量
+	Count    int
+	Depth    int
+	Children []*categoryNode
+
+	// parent 上级分类，顶级分类为 nil
+	parent *categoryNode
+}
+
+// IconIsImage 图标是图片地址而不是 emoji
+func (n *categoryNode) IconIsImage() bool {
+	return strings.Contains(n.Icon, "/")
+}
+
+// Path 从顶级分类到本分类的名称，以 / 分隔
+func (n *categoryNode) Path() string {
+	if n.parent == nil {
+		return n.Name
+	}
+	return n.parent.Path() + " / " + n.Name
+}
+
+// categoryTree 快照中的分类树，包含分类表中的分类和站点中出现但没有定义的分类
+type categoryTree struct {
+	Roots []*categoryNode
+	// List 按树的顺序（先父后子）排列的全部分类
+	List []*categoryNode
+
+	// defined 分类表中的全部分类
+	defined []models.Category
+	bySlug  map[string]*categoryNode
+	// byValue 站点 Category 字段的值对应的分类，值可以是分类标识或名称
+	byValue map[string]*categoryNode
+}
+
+// buildCategoryTree 根据分类表和站点构建分类树并统计每个分类的站点数量
+// 同级分类按排序和名称排列，没有定义的分类按名称排在已定义的顶级分类之后
+func buildCategoryTree(categories []models.Category, sites []models.Site) *categoryTree {
+	t := &categoryTree{
+		defined: categories,
+		bySlug:  make(map[string]*categoryNode, len(categories)),
+		byValue: make(map[string]*categoryNode),
+	}
+	for _, category := range categories {
+		node := &categoryNode{Category: category, Defined: true}
+		t.bySlug[category.Slug] = node
+		if _, ok := t.byValue[category.Name]; !ok {
+			t.byValue[category.Name] = node
+		}
+	}
+	// 标识优先于名称
+	for slug, node := range t.bySlug {
+		t.byValue[slug] = node
+	}
+
+	var undefined []*categoryNode
+	counted := make(map[*categoryNode]bool)
+	for _, site := range sites {
+		if site.Category == "" {
+			continue
+		}
+		node, ok := t.byValue[site.Category]
+		if !ok {
+			node = &categoryNode{Category: models.Category{Slug: site.Category, Name: site.Category}}
+			t.byValue[site.Category] = node
+			undefined = append(undefined, node)
+		}
+		// 分类表应已校验过没有循环，这里仍然避免因循环的上级分类陷入死循环
+		clear(counted)
+		for n := node; n != nil && !counted[n]; n = t.bySlug[n.Parent] {
+			counted[n] = true
+			n.Count++
+		}
+	}
+
+	for _, category := range categories {
+		node := t.bySlug[category.Slug]
+		if parent, ok := t.bySlug[category.Parent]; ok {
+			node.parent = parent
+			parent.Children = append(parent.Children, node)
+		} else {
+			t.Roots = append(t.Roots, node)
+		}
+	}
+	sortCategoryNodes(t.Roots)
+	sort.Slice(undefined, func(i, j int) bool {
+		return undefined[i].Name < undefined[j].Name
+	})
+	t.Roots = append(t.Roots, undefined...)
+
+	var walk func(nodes []*categoryNode, depth int)
+	walk = func(nodes []*categoryNode, depth int) {
+		for _, node := range nodes {
+			node.Depth = depth
+			t.List = append(t.List, node)
+			sortCategoryNodes(node.Children)
+			walk(node.Children, depth+1)
+		}
+	}
+	walk(t.Roots, 0)
+	return t
+}
+
+func sortCategoryNodes(nodes []*categoryNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Order != nodes[j].Order {
+			return nodes[i].Order < nodes[j].Order
+		}
+		return nodes[i].Name < nodes[j].Name
+	})
+}
+
+// resolve 返回站点 Category 字段的值对应的分类
+func (t *categoryTree) resolve(value string) (*categoryNode, bool) {
+	node, ok := t.byValue[value]
+	return node, ok
+}
+
+// matchValues 返回属于 value 对应分类及其全部子分类的站点 Category 值，用于按分类筛选站点
+// value 不对应任何分类时只匹配相同的值
+func (t *categoryTree) matchValues(value string) map[string]bool {
+	node, ok := t.resolve(value)
+	if !ok {
+		return map[string]bool{value: true}
+	}
+	values := make(map[string]bool)
+	for v, n := range t.byValue {
+		for ; n != nil; n = n.parent {
+			if n == node {
+				values[v] = true
+				break
+			}
+		}
+	}
+	return values
+}
+
+// categoryNavItem 首页分类导航中的一项
+type categoryNavItem struct {
+	*categoryNode
+	Active bool
+}
+
+// categoryNav 返回首页分类导航，按树的顺序排列，没有站点的分类不显示
+func (t *categoryTree) categoryNav(selected string) []categoryNavItem {
+	current, _ := t.resolve(selected)
+	var items []categoryNavItem
+	for _, node := range t.List {
+		if node.Count > 0 {
+			items = append(items, categoryNavItem{categoryNode: node, Active: node == current})
+		}
+	}
+	return items
+}
+
+// categoryOption 站点表单中分类下拉框的一项
+type categoryOption struct {
+	Value    string
+	Label    string
+	Selected bool
+}
+
+// categoryOptions 返回站点表单中的分类选项，current 为站点当前的 Category 值
+// 当前分类的选项沿用站点原来的值，只修改其它字段时不会把以名称记录的分类改写为标识
+func (t *categoryTree) categoryOptions(current string) []categoryOption {
+	selected, _ := t.resolve(current)
+	options := make([]categoryOption, 0, len(t.List))
+	for _, node := range t.List {
+		option := categoryOption{Value: node.Slug, Label: strings.Repeat("　", node.Depth) + node.Name}
+		if !node.Defined {
+			option.Label += "（未定义）"
+		}
+		if node == selected {
+			option.Value, option.Selected = current, true
+		}
+		options = append(options, option)
+	}
+	if current != "" && selected == nil {
+		options = append(options, categoryOption{Value: current, Label: current + "（未定义）", Selected: true})
+	}
+	return options
+}
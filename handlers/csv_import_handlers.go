@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"ai-navigator/models"
+	"ai-navigator/store"
+	"ai-navigator/utils"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCSVImportSize 批量导入 CSV 文件的大小上限
+const maxCSVImportSize = 5 << 20
+
+// CSV 批量导入中每一行的处理结果
+const (
+	csvRowNew       = "new"       // 新建站点
+	csvRowUpdate    = "update"    // 按 id 更新现有站点
+	csvRowUnchanged = "unchanged" // id 对应的站点没有变化，跳过
+	csvRowConflict  = "conflict"  // 与现有站点或文件中其它行冲突，跳过
+	csvRowInvalid   = "invalid"   // 字段无效，跳过
+)
+
+// csvImportRow 试运行结果中的一行
+type csvImportRow struct {
+	Line    int
+	Status  string
+	Site    models.Site
+	Before  *models.Site
+	Changes []models.FieldChange
+	Reasons []string
+}
+
+// Accepted 该行是否会在确认导入时保存
+func (r csvImportRow) Accepted() bool {
+	return r.Status == csvRowNew || r.Status == csvRowUpdate
+}
+
+// planCSVImport 试运行：逐行判断 CSV 中的站点会被新建、更新还是跳过，不修改任何数据
+// 填写了 id 且对应现有站点的行会更新该站点，只覆盖 CSV 中出现的列；其余行新建站点
+func planCSVImport(data []byte) ([]csvImportRow, error) {
+	rows, columns, err := store.ParseSitesCSV("CSV", data)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := currentCatalog()
+
+	seenID := make(map[string]int)
+	seenURL := make(map[string]int)
+	plan := make([]csvImportRow, len(rows))
+	for i, row := range rows {
+		result := csvImportRow{Line: row.Line, Site: row.Site, Status: csvRowNew}
+		for _, e := range row.Errors {
+			result.Reasons = append(result.Reasons, e.Reason)
+		}
+
+		conflict := func(format string, args ...interface{}) {
+			result.Reasons = append(result.Reasons, fmt.Sprintf(format, args...))
+		}
+
+		if current, ok := snapshot.site(row.Site.ID); ok && row.Site.ID != "" {
+			merged := current
+			store.ApplyCSVColumns(&merged, row.Site, columns)
+			result.Site = merged
+			result.Before = &current
+			result.Status = csvRowUpdate
+		}
+
+		key := utils.NormalizeURL(result.Site.URL)
+		if other, ok := snapshot.siteByURL(result.Site.URL); ok && result.Site.URL != "" && other.ID != result.Site.ID {
+			if result.Status == csvRowNew {
+				conflict("网址与现有站点 %s 相同，如需更新请在 id 列填写 %s", other.Name, other.ID)
+			} else {
+				conflict("网址与现有站点 %s（%s）相同", other.Name, other.ID)
+			}
+		}
+		if line, ok := seenID[result.Site.ID]; ok && result.Site.ID != "" {
+			conflict("id 与第 %d 行重复", line)
+		}
+		if line, ok := seenURL[key]; ok && result.Site.URL != "" {
+			conflict("网址与第 %d 行重复", line)
+		}
+		if result.Site.ID != "" {
+			seenID[result.Site.ID] = row.Line
+		}
+		seenURL[key] = row.Line
+
+		switch {
+		case len(row.Errors) > 0:
+			result.Status = csvRowInvalid
+		case len(result.Reasons) > 0:
+			result.Status = csvRowConflict
+		}
+		if result.Status == csvRowNew || result.Status == csvRowUpdate {
+			if errs := store.ValidateSite(result.Site); len(errs) > 0 {
+				result.Status = csvRowInvalid
+				for _, e := range errs {
+					result.Reasons = append(result.Reasons, e.Reason)
+				}
+			}
+		}
+		if result.Status == csvRowUpdate {
+			result.Changes = models.DiffSites(result.Before, &result.Site)
+			if len(result.Changes) == 0 {
+				result.Status = csvRowUnchanged
+			}
+		}
+		plan[i] = result
+	}
+	return plan, nil
+}
+
+// csvImportCounts 按处理结果统计行数
+func csvImportCounts(plan []csvImportRow) map[string]int {
+	counts := make(map[string]int)
+	for _, row := range plan {
+		counts[row.Status]++
+	}
+	counts["accepted"] = counts[csvRowNew] + counts[csvRowUpdate]
+	return counts
+}
+
+func AdminImportCSVHandler(c *gin.Context) {
+	c.HTML(http.StatusOK, "admin-import-csv.html", gin.H{
+		"isAdmin": true,
+	})
+}
+
+// AdminImportCSVPostHandler 解析上传的 CSV 并显示试运行结果，此时不会保存任何数据
+func AdminImportCSVPostHandler(c *gin.Context) {
+	renderError := func(msg string) {
+		c.HTML(http.StatusOK, "admin-import-csv.html", gin.H{
+			"error":   msg,
+			"isAdmin": true,
+		})
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		renderError("请选择 CSV 文件")
+		return
+	}
+	if header.Size > maxCSVImportSize {
+		renderError(fmt.Sprintf("CSV 文件不能超过 %d MB", maxCSVImportSize>>20))
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		renderError("读取上传文件失败")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		renderError("读取上传文件失败")
+		return
+	}
+
+	plan, err := planCSVImport(data)
+	if err != nil {
+		renderError("无法解析 CSV 文件: " + err.Error())
+		return
+	}
+	if len(plan) == 0 {
+		renderError("CSV 文件中没有数据行")
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin-import-csv.html", gin.H{
+		"plan":     plan,
+		"counts":   csvImportCounts(plan),
+		"filename": header.Filename,
+		"data":     base64.StdEncoding.EncodeToString(data),
+		"isAdmin":  true,
+	})
+}
+
+// AdminImportCSVConfirmHandler 按最新数据重新试运行后，在一次写入中保存全部可导入的行
+func AdminImportCSVConfirmHandler(c *gin.Context) {
+	data, err := base64.StdEncoding.DecodeString(c.PostForm("data"))
+	if err != nil || len(data) == 0 {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": "导入数据无效，请重新上传 CSV 文件",
+		})
+		return
+	}
+
+	plan, err := planCSVImport(data)
+	if err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": "无法解析 CSV 文件: " + err.Error(),
+		})
+		return
+	}
+
+	var batch []models.Site
+	var before []*models.Site
+	for _, row := range plan {
+		if row.Accepted() {
+			batch = append(batch, row.Site)
+			before = append(before, row.Before)
+		}
+	}
+
+	if len(batch) > 0 {
+		if _, err := saveImported(c, "CSV 批量导入", batch, before); err != nil {
+			if msg, ok := validationMessage(err); ok {
+				c.HTML(http.StatusOK, "admin-import-csv.html", gin.H{
+					"error":   msg,
+					"isAdmin": true,
+				})
+				return
+			}
+			renderStoreError(c, err)
+			return
+		}
+	}
+
+	c.HTML(http.StatusOK, "admin-import-csv.html", gin.H{
+		"imported": true,
+		"counts":   csvImportCounts(plan),
+		"isAdmin":  true,
+	})
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"ai-navigator/models"
+	"ai-navigator/store"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// conflictField 冲突页面中的一个字段，三方的值均为展示用的文本
+type conflictField struct {
+	Name     string
+	Base     string
+	Upstream string
+	Ours     string
+	// Choice 当前选择的一方：upstream、ours 或 manual，Manual 为手动合并的值
+	Choice string
+	Manual string
+}
+
+func AdminConflictsHandler(c *gin.Context) {
+	drift, ok := siteStore.(store.Drift)
+	if !ok {
+		c.HTML(http.StatusOK, "admin-conflicts.html", gin.H{
+			"unsupported": true,
+			"isAdmin":     true,
+		})
+		return
+	}
+
+	conflicts, err := drift.Conflicts()
+	if err != nil {
+		renderStoreError(c, err)
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin-conflicts.html", gin.H{
+		"conflicts": conflicts,
+		"resolved":  c.Query("resolved"),
+		"isAdmin":   true,
+	})
+}
+
+func AdminConflictHandler(c *gin.Context) {
+	drift, ok := siteStore.(store.Drift)
+	if !ok {
+		c.Redirect(http.StatusFound, "/admin/conflicts")
+		return
+	}
+
+	conflict, err := findConflict(drift, c.Param("id"))
+	if err != nil {
+		renderStoreError(c, err)
+		return
+	}
+
+	renderConflict(c, conflict, "")
+}
+
+// AdminResolveConflictPostHandler 按逐字段的选择合并站点包和后台的修改
+// accept 为 upstream 或 ours 时整个站点采用同一方，忽略逐字段的选择
+func AdminResolveConflictPostHandler(c *gin.Context) {
+	drift, ok := siteStore.(store.Drift)
+	if !ok {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": "当前存储不支持冲突合并",
+		})
+		return
+	}
+
+	conflict, err := findConflict(drift, c.Param("id"))
+	if err != nil {
+		renderStoreError(c, err)
+		return
+	}
+
+	var merged models.Site
+	switch c.PostForm("accept") {
+	case "upstream":
+		merged = conflict.Upstream
+	case "ours":
+		merged = conflict.Ours
+	default:
+		merged, err = mergeConflict(c, conflict)
+		if err != nil {
+			renderConflict(c, conflict, err.Error())
+			return
+		}
+	}
+
+	merged, err = drift.ResolveConflict(conflict.ID, merged)
+	if msg, ok := validationMessage(err); ok {
+		renderConflict(c, conflict, msg)
+		return
+	}
+	if err != nil {
+		renderStoreError(c, err)
+		return
+	}
+
+	recordRevision(c, models.RevisionUpdate, &conflict.Ours, &merged)
+	sitesSaved()
+
+	c.Redirect(http.StatusFound, "/admin/conflicts?resolved="+url.QueryEscape(merged.Name))
+}
+
+// siteConflictFields 返回站点与站点包冲突的字段，没有冲突或存储不支持时返回 nil
+func siteConflictFields(id string) []string {
+	drift, ok := siteStore.(store.Drift)
+	if !ok {
+		return nil
+	}
+	conflict, err := findConflict(drift, id)
+	if err != nil {
+		return nil
+	}
+	return conflict.Fields
+}
+
+// renderConflict 显示冲突合并页面，errMsg 不为空时一并显示错误
+func renderConflict(c *gin.Context, conflict store.Conflict, errMsg string) {
+	c.HTML(http.StatusOK, "admin-conflict.html", gin.H{
+		"error":    errMsg,
+		"conflict": conflict,
+		"fields":   conflictFields(c, conflict),
+		"isAdmin":  true,
+	})
+}
+
+// findConflict 查找站点的冲突，站点不存在或没有冲突时返回 store.ErrNotFound
+func findConflict(drift store.Drift, id string) (store.Conflict, error) {
+	conflicts, err := drift.Conflicts()
+	if err != nil {
+		return store.Conflict{}, err
+	}
+	for _, conflict := range conflicts {
+		if conflict.ID == id {
+			return conflict, nil
+		}
+	}
+	return store.Conflict{}, store.ErrNotFound
+}
+
+// conflictFields 生成冲突字段的表单数据，提交后重新显示时保留已填写的选择
+func conflictFields(c *gin.Context, conflict store.Conflict) []conflictField {
+	fields := make([]conflictField, len(conflict.Fields))
+	for i, name := range conflict.Fields {
+		fields[i] = conflictField{
+			Name:     name,
+			Base:     models.FieldValue(conflict.Base, name),
+			Upstream: models.FieldValue(conflict.Upstream, name),
+			Ours:     models.FieldValue(conflict.Ours, name),
+			Choice:   c.DefaultPostForm("choice_"+name, "ours"),
+		}
+		fields[i].Manual = c.DefaultPostForm("value_"+name, fields[i].Ours)
+	}
+	return fields
+}
+
+// mergeConflict 以后台的修改为基础，按表单中的选择替换冲突字段
+func mergeConflict(c *gin.Context, conflict store.Conflict) (models.Site, error) {
+	merged := conflict.Ours
+	merged.Tags = append([]string(nil), conflict.Ours.Tags...)
+	dst := reflect.ValueOf(&merged).Elem()
+	for _, name := range conflict.Fields {
+		i, _ := models.SiteFieldIndex(name)
+		switch c.PostForm("choice_" + name) {
+		case "upstream":
+			dst.Field(i).Set(reflect.ValueOf(conflict.Upstream).Field(i))
+		case "manual":
+			if err := parseFieldValue(dst.Field(i), c.PostForm("value_"+name)); err != nil {
+				return models.Site{}, fmt.Errorf("字段 %s 的值无效：%v", name, err)
+			}
+		}
+	}
+	return merged, nil
+}
+
+// parseFieldValue 将手动填写的文本解析为字段的值，格式与页面上展示的相同
+func parseFieldValue(v reflect.Value, text string) error {
+	text = strings.TrimSpace(text)
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Slice:
+		items := []string{}
+		for _, item := range strings.Split(text, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	case reflect.Float64:
+		f := 0.0
+		if text != "" {
+			var err error
+			if f, err = strconv.ParseFloat(text, 64); err != nil {
+				return err
+			}
+		}
+		v.SetFloat(f)
+	case reflect.Int:
+		n := 0
+		if text != "" {
+			var err error
+			if n, err = strconv.Atoi(text); err != nil {
+				return err
+			}
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		v.SetBool(text == "是" || text == "true" || text == "on")
+	default:
+		return fmt.Errorf("不支持的字段类型 %s", v.Kind())
+	}
+	return nil
+}
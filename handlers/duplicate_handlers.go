@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"ai-navigator/models"
+	"cmp"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 可能重复的原因
+const (
+	duplicateURL  = "网址相同"
+	duplicateName = "名称相似"
+)
+
+// duplicateMatch 与正在保存的站点可能重复的现有站点
+type duplicateMatch struct {
+	Site   models.Site
+	Reason string
+}
+
+// duplicatePair 一对可能重复的站点
+type duplicatePair struct {
+	A, B   models.Site
+	Reason string
+}
+
+// duplicateReason 判断两个站点是否可能重复：网址的主机名和路径相同，或名称相似
+// da、db 为两个站点的预计算数据
+func duplicateReason(a models.Site, da, db siteDerived) (string, bool) {
+	if a.URL != "" && da.hostPath == db.hostPath {
+		return duplicateURL, true
+	}
+	if da.name.Similar(db.name) {
+		return duplicateName, true
+	}
+	return "", false
+}
+
+// findDuplicatesOf 在当前快照的站点中查找与 site 可能重复的站点，不包括 site 自身
+func findDuplicatesOf(site models.Site) []duplicateMatch {
+	snapshot := currentCatalog()
+	d := deriveSite(site, 0)
+
+	var matches []duplicateMatch
+	for i, other := range snapshot.Sites {
+		if other.ID == site.ID {
+			continue
+		}
+		if reason, ok := duplicateReason(site, d, snapshot.derived[i]); ok {
+			matches = append(matches, duplicateMatch{Site: other, Reason: reason})
+		}
+	}
+	return matches
+}
+
+// findDuplicatePairs 找出全部可能重复的站点对，网址相同的排在前面
+// 站点数量很大时两两比较太慢：先按网址的主机名和路径、名称的分组键（utils.NameKeys.BlockHashes）分组，
+// 只比较同一组中的站点；包含关系通过较长名称的片段查找被包含的名称
+func findDuplicatePairs(all []models.Site, derived []siteDerived) []duplicatePair {
+	type blockEntry struct {
+		hash uint64
+		site int
+	}
+	var entries []blockEntry
+	contained := make(map[uint64][]int)
+	for i, site := range all {
+		if site.URL != "" {
+			entries = append(entries, blockEntry{hash: blockHash(derived[i].hostPath), site: i})
+		}
+		for _, hash := range derived[i].name.BlockHashes() {
+			entries = append(entries, blockEntry{hash: hash, site: i})
+		}
+		if derived[i].name.CanBeContained() {
+			hash := derived[i].name.KeyHash()
+			contained[hash] = append(contained[hash], i)
+		}
+	}
+	slices.SortFunc(entries, func(a, b blockEntry) int {
+		return cmp.Or(cmp.Compare(a.hash, b.hash), cmp.Compare(a.site, b.site))
+	})
+
+	found := make(map[[2]int]string)
+	check := func(i, j int) {
+		if i == j {
+			return
+		}
+		key := [2]int{min(i, j), max(i, j)}
+		if _, ok := found[key]; ok {
+			return
+		}
+		if reason, ok := duplicateReason(all[key[0]], derived[key[0]], derived[key[1]]); ok {
+			found[key] = reason
+		}
+	}
+	for start := 0; start < len(entries); {
+		end := start + 1
+		for end < len(entries) && entries[end].hash == entries[start].hash {
+			end++
+		}
+		group := entries[start:end]
+		for x, a := range group {
+			for _, b := range group[x+1:] {
+				check(a.site, b.site)
+			}
+		}
+		start = end
+	}
+	for i := range all {
+		derived[i].name.EachPart(func(hash uint64) {
+			for _, j := range contained[hash] {
+				check(i, j)
+			}
+		})
+	}
+
+	keys := make([][2]int, 0, len(found))
+	for key := range found {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b [2]int) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[1], b[1]))
+	})
+
+	var byURL, byName []duplicatePair
+	for _, key := range keys {
+		pair := duplicatePair{A: all[key[0]], B: all[key[1]], Reason: found[key]}
+		if pair.Reason == duplicateURL {
+			byURL = append(byURL, pair)
+		} else {
+			byName = append(byName, pair)
+		}
+	}
+	return append(byURL, byName...)
+}
+
+// blockHash 网址分组键的哈希，不同的键哈希相同时只会多比较几对站点
+func blockHash(hostPath string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte("url:"))
+	h.Write([]byte(hostPath))
+	return h.Sum64()
+}
+
+func AdminDuplicatesHandler(c *gin.Context) {
+	c.HTML(http.StatusOK, "admin-duplicates.html", gin.H{
+		"pairs":   currentCatalog().Duplicates(),
+		"merged":  c.Query("merged"),
+		"isAdmin": true,
+	})
+}
+
+// AdminMergeDuplicatesHandler 将 drop 合并到 keep：合并标签并保留两者中更完整的字段，drop 移入回收站
+// 合并后的 keep 和标记为删除的 drop 在一次写入中保存，不会只保存其中一个
+func AdminMergeDuplicatesHandler(c *gin.Context) {
+	keep, err := siteStore.Get(c.PostForm("keep"))
+	if err != nil {
+		renderStoreError(c, err)
+		return
+	}
+	drop, err := siteStore.Get(c.PostForm("drop"))
+	if err != nil {
+		renderStoreError(c, err)
+		return
+	}
+	if keep.ID == drop.ID {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": "不能将站点与自身合并",
+		})
+		return
+	}
+
+	merged := mergeDuplicate(keep, drop)
+	deleted := drop
+	deleted.Deleted = true
+	deleted.DeletedAt = time.Now().Format(time.RFC3339)
+	deleted.DeletedBy = currentAdmin(c)
+	saved, err := siteStore.SaveAll([]models.Site{merged, deleted})
+	if err != nil {
+		if msg, ok := validationMessage(err); ok {
+			c.HTML(http.StatusBadRequest, "error.html", gin.H{
+				"error": msg,
+			})
+			return
+		}
+		renderStoreError(c, err)
+		return
+	}
+
+	revs := []models.Revision{
+		saveRevision(c, models.RevisionUpdate, &keep, &saved[0]),
+		saveRevision(c, models.RevisionDelete, &drop, &saved[1]),
+	}
+	commitChange(c, batchCommitMessage(fmt.Sprintf("合并重复站点：%s 并入 %s", drop.Name, keep.Name), revs))
+	sitesSaved()
+
+	c.Redirect(http.StatusFound, "/admin/duplicates?merged="+url.QueryEscape(keep.Name))
+}
+
+// mergeDuplicate 合并两个重复的站点：以 keep 为准，keep 缺少的字段取 drop 的值，
+// 标签取并集，描述取较长的一个，评分取较高的一个，访问量相加，创建时间取较早的一个
+func mergeDuplicate(keep, drop models.Site) models.Site {
+	merged := keep
+	merged.Pack, merged.Overridden = "", false
+
+	if len([]rune(drop.Description)) > len([]rune(merged.Description)) {
+		merged.Description = drop.Description
+	}
+	if merged.Logo == "" {
+		merged.Logo = drop.Logo
+	}
+	if merged.Category == "" {
+		merged.Category = drop.Category
+	}
+	if merged.Pricing == nil {
+		merged.Pricing = drop.Pricing
+	}
+	if drop.Rating > merged.Rating {
+		merged.Rating = drop.Rating
+	}
+	merged.Featured = keep.Featured || drop.Featured
+	merged.Visits = keep.Visits + drop.Visits
+	if merged.CreatedAt == "" || (drop.CreatedAt != "" && drop.CreatedAt < merged.CreatedAt) {
+		merged.CreatedAt = drop.CreatedAt
+	}
+
+	merged.Tags = currentCatalog().Tags.normalize(append(slices.Clone(keep.Tags), drop.Tags...))
+	return merged
+}
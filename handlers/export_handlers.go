@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"ai-navigator/config"
+	"ai-navigator/exchange"
+	"ai-navigator/models"
+	"ai-navigator/store"
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportContentTypes 各导出格式对应的 Content-Type
+var exportContentTypes = map[string]string{
+	store.FormatJSON: "application/json; charset=utf-8",
+	store.FormatYAML: "application/yaml; charset=utf-8",
+	store.FormatCSV:  "text/csv; charset=utf-8",
+}
+
+// AdminExportHandler 以 JSON、YAML 或 CSV 格式下载当前全部站点
+func AdminExportHandler(c *gin.Context) {
+	format := c.DefaultQuery("format", store.FormatJSON)
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": "不支持的导出格式",
+		})
+		return
+	}
+
+	data, err := store.EncodeSites(format, currentCatalog().Sites)
+	if err != nil {
+		log.Printf("导出站点数据失败: %v", err)
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"error": "导出站点数据失败",
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("sites-%s.%s", time.Now().Format("20060102"), format)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// BookmarksHandler 将当前站点按分类导出为浏览器书签文件，可直接导入 Chrome、Firefox、Edge
+func BookmarksHandler(c *gin.Context) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="ai-later-bookmarks.html"`)
+	if err := exchange.WriteBookmarks(c.Writer, "AI Later", namedCategorySites()); err != nil {
+		log.Printf("导出书签文件失败: %v", err)
+	}
+}
+
+// AdminExportWebStackHandler 将当前站点导出为 WebStack 数据文件，可放到 WebStack 类导航站点的 data 目录中使用
+func AdminExportWebStackHandler(c *gin.Context) {
+	c.Header("Content-Type", "application/yaml; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="webstack.yml"`)
+	if err := exchange.WriteWebStack(c.Writer, namedCategorySites()); err != nil {
+		log.Printf("导出 WebStack 数据失败: %v", err)
+	}
+}
+
+// AdminExportAwesomeHandler 将当前站点按分类渲染为 awesome 列表 Markdown 并下载
+func AdminExportAwesomeHandler(c *gin.Context) {
+	cfg := config.AppConfig.Awesome
+	tmpl, err := exchange.ParseAwesomeTemplate(cfg.Template)
+	if err != nil {
+		log.Printf("加载 awesome 列表模板失败: %v", err)
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"error": "加载 awesome 列表模板失败: " + err.Error(),
+		})
+		return
+	}
+
+	snapshot := currentCatalog()
+	sites := models.WithCategoryNames(snapshot.Sites, snapshot.Categories.defined)
+	var buf bytes.Buffer
+	list := exchange.NewAwesomeList(cfg.Title, cfg.Description, sites, snapshot.Categories.defined, time.Now())
+	if err := exchange.WriteAwesome(&buf, tmpl, list); err != nil {
+		log.Printf("导出 awesome 列表失败: %v", err)
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="README.md"`)
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", buf.Bytes())
+}
+
+// namedCategorySites 返回分类显示为名称的当前站点，书签、WebStack 和 awesome 列表中的分类都是给人看的
+func namedCategorySites() []models.Site {
+	snapshot := currentCatalog()
+	return models.WithCategoryNames(snapshot.Sites, snapshot.Categories.defined)
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"ai-navigator/models"
+	"ai-navigator/store"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// commitLogLimit 提交记录页面显示的提交数量
+const commitLogLimit = 100
+
+// revisionActionLabels 修订操作在提交信息中的名称
+var revisionActionLabels = map[string]string{
+	models.RevisionCreate:  "新建",
+	models.RevisionUpdate:  "编辑",
+	models.RevisionDelete:  "删除",
+	models.RevisionRestore: "恢复",
+	models.RevisionPurge:   "彻底删除",
+}
+
+// commitChange 将本次后台修改提交到 git 版本库，存储未启用 git 提交时忽略
+// 数据已经保存成功，提交失败只记录日志
+func commitChange(c *gin.Context, message string) {
+	commitChangeAs(currentAdmin(c), message)
+}
+
+// commitChangeAs 以 author 的名义提交，用于自动清理等没有请求上下文的操作
+func commitChangeAs(author, message string) {
+	committer, ok := siteStore.(store.Committer)
+	if !ok {
+		return
+	}
+	if err := committer.Commit(message, author); err != nil {
+		log.Printf("提交数据变更到 git 失败: %v", err)
+	}
+}
+
+// revisionCommitMessage 根据修订生成提交信息：首行说明操作和站点，正文列出字段变化
+func revisionCommitMessage(rev models.Revision) string {
+	var b strings.Builder
+	b.WriteString(revisionSummary(rev) + "\n\n")
+	writeFieldChanges(&b, rev)
+	fmt.Fprintf(&b, "\n操作人: %s\n", rev.Actor)
+	return b.String()
+}
+
+// batchCommitMessage 批量操作的提交信息：首行为 subject，正文逐个列出修订
+func batchCommitMessage(subject string, revs []models.Revision) string {
+	var b strings.Builder
+	b.WriteString(subject + "\n\n")
+	for _, rev := range revs {
+		b.WriteString(revisionSummary(rev) + "\n")
+		if rev.Action == models.RevisionUpdate {
+			writeFieldChanges(&b, rev)
+		}
+	}
+	if len(revs) > 0 {
+		fmt.Fprintf(&b, "\n操作人: %s\n", revs[0].Actor)
+	}
+	return b.String()
+}
+
+// revisionSummary 一句话描述修订，例如「编辑站点 ChatGPT（chatgpt）」
+func revisionSummary(rev models.Revision) string {
+	name := rev.SiteID
+	if snapshot := rev.Snapshot(); snapshot != nil && snapshot.Name != "" {
+		name = snapshot.Name
+	} else if rev.Before != nil && rev.Before.Name != "" {
+		name = rev.Before.Name
+	}
+
+	action, ok := revisionActionLabels[rev.Action]
+	if !ok {
+		action = rev.Action
+	}
+	return fmt.Sprintf("%s站点 %s（%s）", action, name, rev.SiteID)
+}
+
+func writeFieldChanges(b *strings.Builder, rev models.Revision) {
+	for _, change := range models.DiffSites(rev.Before, rev.After) {
+		fmt.Fprintf(b, "%s: %s → %s\n", change.Field, change.Before, change.After)
+	}
+}
+
+func AdminCommitLogHandler(c *gin.Context) {
+	committer, ok := siteStore.(store.Committer)
+	if !ok {
+		c.HTML(http.StatusOK, "admin-commits.html", gin.H{
+			"unsupported": true,
+			"isAdmin":     true,
+		})
+		return
+	}
+
+	commits, err := committer.CommitLog(commitLogLimit)
+	if errors.Is(err, store.ErrGitDisabled) {
+		c.HTML(http.StatusOK, "admin-commits.html", gin.H{
+			"unsupported": true,
+			"isAdmin":     true,
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("读取提交记录失败: %v", err)
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"error": "读取提交记录失败",
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin-commits.html", gin.H{
+		"commits": commits,
+		"isAdmin": true,
+	})
+}
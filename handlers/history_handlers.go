@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"ai-navigator/models"
+	"ai-navigator/store"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// currentAdmin 返回当前登录的管理员用户名
+func currentAdmin(c *gin.Context) string {
+	if user, ok := sessions.Default(c).Get("admin_user").(string); ok && user != "" {
+		return user
+	}
+	return "admin"
+}
+
+// recordRevision 记录一次站点修改，启用 git 提交时同时提交本次修改
+func recordRevision(c *gin.Context, action string, before, after *models.Site) {
+	rev := saveRevision(c, action, before, after)
+	commitChange(c, revisionCommitMessage(rev))
+}
+
+// saveRevision 只记录修订历史，不产生 git 提交，批量操作由调用方统一提交
+// 存储不支持修订历史时忽略
+func saveRevision(c *gin.Context, action string, before, after *models.Site) models.Revision {
+	return saveRevisionAs(currentAdmin(c), action, before, after)
+}
+
+// saveRevisionAs 以 actor 的名义记录修订历史，用于自动清理等没有请求上下文的操作
+func saveRevisionAs(actor, action string, before, after *models.Site) models.Revision {
+	siteID := ""
+	if after != nil {
+		siteID = after.ID
+	} else if before != nil {
+		siteID = before.ID
+	}
+
+	rev := models.Revision{
+		SiteID: siteID,
+		Action: action,
+		Actor:  actor,
+		Time:   time.Now(),
+		Before: before,
+		After:  after,
+	}
+	if revLog, ok := siteStore.(store.RevisionLog); ok {
+		if _, err := revLog.RecordRevision(rev); err != nil {
+			log.Printf("记录站点 %s 的修订历史失败: %v", siteID, err)
+		}
+	}
+	return rev
+}
+
+// revisionView 修订记录及其相对上一状态的字段差异
+type revisionView struct {
+	models.Revision
+	Changes []models.FieldChange
+}
+
+func AdminSiteHistoryHandler(c *gin.Context) {
+	id := c.Param("id")
+
+	revLog, ok := siteStore.(store.RevisionLog)
+	if !ok {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": "当前存储不支持修订历史",
+		})
+		return
+	}
+
+	revisions, err := revLog.ListRevisions(id)
+	if err != nil {
+		renderStoreError(c, err)
+		return
+	}
+
+	views := make([]revisionView, len(revisions))
+	for i, rev := range revisions {
+		views[i] = revisionView{Revision: rev, Changes: models.DiffSites(rev.Before, rev.After)}
+	}
+
+	data := gin.H{
+		"siteID":    id,
+		"revisions": views,
+		"isAdmin":   true,
+	}
+	if site, err := siteStore.Get(id); err == nil {
+		data["site"] = site
+	}
+
+	// 选择了两个修订时展示它们之间的差异
+	from, errFrom := strconv.ParseInt(c.Query("from"), 10, 64)
+	to, errTo := strconv.ParseInt(c.Query("to"), 10, 64)
+	if errFrom == nil && errTo == nil {
+		fromRev, err := findRevision(revisions, from)
+		if err == nil {
+			var toRev models.Revision
+			toRev, err = findRevision(revisions, to)
+			if err == nil {
+				data["compareFrom"] = fromRev
+				data["compareTo"] = toRev
+				data["compareChanges"] = models.DiffSites(fromRev.Snapshot(), toRev.Snapshot())
+			}
+		}
+		if err != nil {
+			data["error"] = "所选修订记录不存在"
+		}
+	}
+
+	c.HTML(http.StatusOK, "admin-site-history.html", data)
+}
+
+func AdminRestoreRevisionHandler(c *gin.Context) {
+	id := c.Param("id")
+
+	revLog, ok := siteStore.(store.RevisionLog)
+	if !ok {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": "当前存储不支持修订历史",
+		})
+		return
+	}
+
+	revID, err := strconv.ParseInt(c.Param("rev"), 10, 64)
+	if err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": "无效的修订编号",
+		})
+		return
+	}
+
+	rev, err := revLog.GetRevision(revID)
+	if err != nil || rev.SiteID != id || rev.Snapshot() == nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "修订记录不存在",
+		})
+		return
+	}
+
+	if err := restoreSnapshot(c, id, *rev.Snapshot()); err != nil {
+		renderStoreError(c, err)
+		return
+	}
+
+	sitesSaved()
+
+	c.Redirect(http.StatusFound, fmt.Sprintf("/admin/sites/history/%s", id))
+}
+
+// restoreSnapshot 将站点恢复为快照中的状态，并记录一次恢复操作
+func restoreSnapshot(c *gin.Context, id string, snapshot models.Site) error {
+	snapshot.ID = id
+
+	current, err := siteStore.Get(id)
+	exists := err == nil
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+
+	switch {
+	case snapshot.Deleted && !exists:
+		// 快照本身就是删除状态，站点已经不存在
+		return nil
+	case snapshot.Deleted:
+		err = siteStore.Delete(id, currentAdmin(c))
+	case exists:
+		err = siteStore.Update(id, snapshot)
+	default:
+		_, err = siteStore.Create(snapshot)
+	}
+	if err != nil {
+		return err
+	}
+
+	var before *models.Site
+	if exists {
+		before = &current
+	}
+	recordRevision(c, models.RevisionRestore, before, &snapshot)
+	return nil
+}
+
+func findRevision(revisions []models.Revision, id int64) (models.Revision, error) {
+	for _, rev := range revisions {
+		if rev.ID == id {
+			return rev, nil
+		}
+	}
+	return models.Revision{}, store.ErrRevisionNotFound
+}
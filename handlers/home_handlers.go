@@ -1,17 +1,18 @@
 package handlers
 
 import (
+	"ai-navigator/models"
 	"log"
 	"net/http"
+	"slices"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 func HomeHandler(c *gin.Context) {
-	sitesLock.RLock()
-	defer sitesLock.RUnlock()
-
-	if len(sites) == 0 {
+	snapshot := currentCatalog()
+	if len(snapshot.Sites) == 0 {
 		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
 			"error": "无法加载站点数据",
 		})
@@ -20,31 +21,77 @@ func HomeHandler(c *gin.Context) {
 	}
 
 	copyright, _ := c.Get("Copyright")
-	displaySites := getDisplaySites()
+	page, start, end := paginate(c, len(snapshot.Display))
 
+	setCatalogVersion(c, snapshot)
 	c.HTML(http.StatusOK, "index.html", gin.H{
-		"sites":      displaySites,
-		"categories": getUniqueCategories(sites),
-		"Copyright":  copyright,
+		"sites":          snapshot.Display[start:end],
+		"pagination":     page,
+		"categoryNav":    snapshot.Categories.categoryNav(""),
+		"pricingFilters": pricingFilters(c, ""),
+		"Copyright":      copyright,
 	})
 }
 
 func SearchHandler(c *gin.Context) {
-	sitesLock.RLock()
-	defer sitesLock.RUnlock()
+	snapshot := currentCatalog()
 
 	query := c.Query("q")
 	category := c.Query("category")
 	sortBy := c.Query("sort")
+	pricing := c.Query("pricing")
 
-	displaySites := getDisplaySites()
-	filtered := filterDisplaySites(displaySites, query, category, sortBy)
+	var categories map[string]bool
+	if category != "" {
+		categories = snapshot.Categories.matchValues(category)
+	}
+	filtered := filterDisplaySites(snapshot.Display, query, categories, pricing, sortBy)
+	page, start, end := paginate(c, len(filtered))
 
-	c.HTML(http.StatusOK, "index.html", gin.H{
-		"sites":            filtered,
-		"categories":       getUniqueCategories(sites),
+	data := gin.H{
+		"sites":            filtered[start:end],
+		"pagination":       page,
+		"categoryNav":      snapshot.Categories.categoryNav(category),
 		"query":            query,
 		"selectedCategory": category,
 		"selectedSort":     sortBy,
+		"selectedPricing":  pricing,
+		"pricingFilters":   pricingFilters(c, pricing),
+	}
+	if node, ok := snapshot.Categories.resolve(category); ok {
+		data["currentCategory"] = node
+	}
+
+	setCatalogVersion(c, snapshot)
+	c.HTML(http.StatusOK, "index.html", data)
+}
+
+// setCatalogVersion 在响应头中标明本次响应使用的快照版本
+func setCatalogVersion(c *gin.Context, snapshot *catalog) {
+	c.Header("X-Catalog-Version", strconv.FormatUint(snapshot.Version, 10))
+}
+
+// SiteDetailHandler 站点详情页面，显示站点的定价、套餐和价格历史
+func SiteDetailHandler(c *gin.Context) {
+	snapshot := currentCatalog()
+	i, ok := snapshot.byID[c.Param("id")]
+	if !ok {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "站点不存在",
+		})
+		return
+	}
+	site := snapshot.Display[i]
+
+	var history []models.PriceRecord
+	if site.Pricing != nil {
+		history = slices.Clone(site.Pricing.History)
+		slices.Reverse(history)
+	}
+
+	setCatalogVersion(c, snapshot)
+	c.HTML(http.StatusOK, "site.html", gin.H{
+		"site":         site,
+		"priceHistory": history,
 	})
 }
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"ai-navigator/exchange"
+	"ai-navigator/models"
+	"ai-navigator/store"
+	"ai-navigator/utils"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 导入预览中每条记录的状态
+const (
+	importNew       = "new"       // 新站点
+	importDuplicate = "duplicate" // 网址与现有站点相同
+	importRepeated  = "repeated"  // 网址与文件中前面的记录相同
+)
+
+// importCandidate 导入预览中的一条记录
+type importCandidate struct {
+	Index  int
+	Site   models.Site
+	Status string
+	// TagsString 标签以逗号连接，用于预览表单
+	TagsString string
+	// Existing 网址相同的现有站点名称
+	Existing string
+}
+
+// maxImportFileSize 书签和 WebStack 文件的大小上限，与 CSV 批量导入相同
+const maxImportFileSize = maxCSVImportSize
+
+// importFailure 确认导入时跳过或保存失败的记录
+type importFailure struct {
+	Name   string
+	URL    string
+	Reason string
+}
+
+// previewImport 按网址与现有站点及文件中前面的记录比较，标记重复的记录
+func previewImport(incoming []models.Site) []importCandidate {
+	snapshot := currentCatalog()
+
+	seen := make(map[string]bool)
+	candidates := make([]importCandidate, len(incoming))
+	for i, site := range incoming {
+		key := utils.NormalizeURL(site.URL)
+		candidate := importCandidate{Index: i, Site: site, Status: importNew, TagsString: strings.Join(site.Tags, ", ")}
+		if existing, ok := snapshot.siteByURL(site.URL); ok {
+			candidate.Status = importDuplicate
+			candidate.Existing = existing.Name
+		} else if seen[key] {
+			candidate.Status = importRepeated
+		}
+		seen[key] = true
+		candidates[i] = candidate
+	}
+	return candidates
+}
+
+// selectedImportSites 读取预览表单中勾选的记录，表单中每个字段按记录顺序提交
+func selectedImportSites(c *gin.Context) []models.Site {
+	names := c.PostFormArray("name")
+	urls := c.PostFormArray("url")
+	categories := c.PostFormArray("category")
+	descriptions := c.PostFormArray("description")
+	logos := c.PostFormArray("logo")
+	tags := c.PostFormArray("tags")
+
+	field := func(values []string, i int) string {
+		if i < len(values) {
+			return strings.TrimSpace(values[i])
+		}
+		return ""
+	}
+
+	var selected []models.Site
+	for _, v := range c.PostFormArray("selected") {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 || i >= len(urls) {
+			continue
+		}
+		site := models.Site{
+			Name:        field(names, i),
+			URL:         field(urls, i),
+			Category:    field(categories, i),
+			Description: field(descriptions, i),
+			Logo:        field(logos, i),
+			Tags:        utils.SplitTags(field(tags, i)),
+		}
+		selected = append(selected, site)
+	}
+	return selected
+}
+
+// applyImport 保存导入的新站点，其余记录在一次写入中保存
+// 勾选的记录由客户端提交，保存前重新检查重复：与现有站点或前面的记录网址相同、未通过校验的记录跳过并返回原因
+func applyImport(c *gin.Context, label string, incoming []models.Site) (int, []importFailure, error) {
+	var valid []models.Site
+	var failures []importFailure
+	for _, candidate := range previewImport(incoming) {
+		site := candidate.Site
+		switch candidate.Status {
+		case importDuplicate:
+			failures = append(failures, importFailure{Name: site.Name, URL: site.URL, Reason: "网址与现有站点「" + candidate.Existing + "」相同"})
+			continue
+		case importRepeated:
+			failures = append(failures, importFailure{Name: site.Name, URL: site.URL, Reason: "网址与前面的记录相同"})
+			continue
+		}
+		if errs := store.ValidateSite(site); len(errs) > 0 {
+			reason, _ := validationMessage(errs)
+			failures = append(failures, importFailure{Name: site.Name, URL: site.URL, Reason: reason})
+			continue
+		}
+		valid = append(valid, site)
+	}
+	if len(valid) == 0 {
+		return 0, failures, nil
+	}
+
+	saved, err := saveImported(c, label, valid, make([]*models.Site, len(valid)))
+	return len(saved), failures, err
+}
+
+// saveImported 在一次写入中保存批量导入的站点，为每个站点记录修订，启用 git 提交时只产生一次提交
+// before 与 batch 一一对应，新建的站点为 nil；保存前按标签注册表将标签换成规范名称，并记录价格变化
+func saveImported(c *gin.Context, label string, batch []models.Site, before []*models.Site) ([]models.Site, error) {
+	tags := currentCatalog().Tags
+	date := today()
+	for i := range batch {
+		batch[i].Tags = tags.normalize(batch[i].Tags)
+		var pricing *models.Pricing
+		if before[i] != nil {
+			pricing = before[i].Pricing
+		}
+		batch[i].Pricing = batch[i].Pricing.Track(pricing, date)
+	}
+	saved, err := siteStore.SaveAll(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	created, updated := 0, 0
+	revs := make([]models.Revision, len(saved))
+	for i := range saved {
+		action := models.RevisionCreate
+		if before[i] != nil {
+			action = models.RevisionUpdate
+			updated++
+		} else {
+			created++
+		}
+		revs[i] = saveRevision(c, action, before[i], &saved[i])
+	}
+
+	subject := fmt.Sprintf("%s：新建 %d 个站点", label, created)
+	if updated > 0 {
+		subject += fmt.Sprintf("，更新 %d 个站点", updated)
+	}
+	commitChange(c, batchCommitMessage(subject, revs))
+	sitesSaved()
+	return saved, nil
+}
+
+func AdminImportBookmarksHandler(c *gin.Context) {
+	c.HTML(http.StatusOK, "admin-import-bookmarks.html", gin.H{
+		"isAdmin": true,
+	})
+}
+
+// AdminImportBookmarksPostHandler 解析上传的浏览器书签文件并显示导入预览，此时不会保存任何数据
+func AdminImportBookmarksPostHandler(c *gin.Context) {
+	renderError := func(msg string) {
+		c.HTML(http.StatusOK, "admin-import-bookmarks.html", gin.H{
+			"error":   msg,
+			"isAdmin": true,
+		})
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		renderError("请选择浏览器导出的书签文件")
+		return
+	}
+	if header.Size > maxImportFileSize {
+		renderError(fmt.Sprintf("文件不能超过 %d MB", maxImportFileSize>>20))
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		renderError("读取上传文件失败")
+		return
+	}
+	defer file.Close()
+
+	bookmarks, err := exchange.ParseBookmarks(file)
+	if err != nil {
+		log.Printf("解析书签文件 %s 失败: %v", header.Filename, err)
+		renderError("无法解析书签文件，请确认是浏览器导出的 HTML 书签文件")
+		return
+	}
+	if len(bookmarks) == 0 {
+		renderError("书签文件中没有找到 http/https 链接")
+		return
+	}
+
+	defaultCategory := strings.TrimSpace(c.PostForm("Category"))
+	incoming := make([]models.Site, len(bookmarks))
+	for i, b := range bookmarks {
+		site := models.Site{
+			Name:        b.Title,
+			URL:         b.URL,
+			Description: b.Description,
+			Category:    b.Folder(),
+			Tags:        []string{},
+		}
+		if site.Name == "" {
+			if u, err := url.Parse(b.URL); err == nil {
+				site.Name = u.Hostname()
+			}
+		}
+		if site.Category == "" {
+			site.Category = defaultCategory
+		}
+		incoming[i] = site
+	}
+
+	c.HTML(http.StatusOK, "admin-import-bookmarks.html", gin.H{
+		"candidates": previewImport(incoming),
+		"filename":   header.Filename,
+		"isAdmin":    true,
+	})
+}
+
+// AdminImportBookmarksConfirmHandler 保存预览中勾选的书签
+func AdminImportBookmarksConfirmHandler(c *gin.Context) {
+	created, failures, err := applyImport(c, "导入浏览器书签", selectedImportSites(c))
+	if err != nil {
+		renderStoreError(c, err)
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin-import-bookmarks.html", gin.H{
+		"imported": true,
+		"created":  created,
+		"failures": failures,
+		"isAdmin":  true,
+	})
+}
+
+func AdminImportWebStackHandler(c *gin.Context) {
+	c.HTML(http.StatusOK, "admin-import-webstack.html", gin.H{
+		"isAdmin": true,
+	})
+}
+
+// AdminImportWebStackPostHandler 解析上传的 WebStack 数据文件并显示导入预览，此时不会保存任何数据
+func AdminImportWebStackPostHandler(c *gin.Context) {
+	renderError := func(msg string) {
+		c.HTML(http.StatusOK, "admin-import-webstack.html", gin.H{
+			"error":   msg,
+			"isAdmin": true,
+		})
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		renderError("请选择 WebStack 数据文件")
+		return
+	}
+	if header.Size > maxImportFileSize {
+		renderError(fmt.Sprintf("文件不能超过 %d MB", maxImportFileSize>>20))
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		renderError("读取上传文件失败")
+		return
+	}
+	defer file.Close()
+
+	incoming, err := exchange.ParseWebStack(file)
+	if err != nil {
+		log.Printf("解析 WebStack 数据文件 %s 失败: %v", header.Filename, err)
+		renderError("无法解析 WebStack 数据文件: " + err.Error())
+		return
+	}
+	if len(incoming) == 0 {
+		renderError("文件中没有找到任何链接")
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin-import-webstack.html", gin.H{
+		"candidates": previewImport(incoming),
+		"filename":   header.Filename,
+		"isAdmin":    true,
+	})
+}
+
+// AdminImportWebStackConfirmHandler 保存预览中勾选的链接
+func AdminImportWebStackConfirmHandler(c *gin.Context) {
+	created, failures, err := applyImport(c, "导入 WebStack 数据", selectedImportSites(c))
+	if err != nil {
+		renderStoreError(c, err)
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin-import-webstack.html", gin.H{
+		"imported": true,
+		"created":  created,
+		"failures": failures,
+		"isAdmin":  true,
+	})
+}
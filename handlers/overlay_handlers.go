@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"ai-navigator/models"
+	"ai-navigator/store"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// siteOverrides 返回站点相对站点包被修改过的字段，键为 JSON 字段名，站点不来自站点包时返回 nil
+func siteOverrides(site models.Site) map[string]*models.FieldChange {
+	overlay, ok := siteStore.(store.Overlay)
+	if !ok {
+		return nil
+	}
+	upstream, err := overlay.Upstream(site.ID)
+	if err != nil {
+		return nil
+	}
+
+	overrides := make(map[string]*models.FieldChange)
+	for _, change := range models.DiffSites(&upstream, &site) {
+		if strings.HasPrefix(change.Field, "deleted") {
+			continue
+		}
+		change := change
+		overrides[change.Field] = &change
+	}
+	return overrides
+}
+
+// AdminResetSiteFieldHandler 撤销站点对某个字段的修改，恢复为站点包中的值
+func AdminResetSiteFieldHandler(c *gin.Context) {
+	overlay, ok := siteStore.(store.Overlay)
+	if !ok {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": "当前存储不支持恢复站点包的值",
+		})
+		return
+	}
+
+	id := c.Param("id")
+	current, err := siteStore.Get(id)
+	if err != nil {
+		renderStoreError(c, err)
+		return
+	}
+
+	reset, err := overlay.ResetFields(id, c.Param("field"))
+	if errors.Is(err, store.ErrUnknownField) {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": "不能恢复该字段：" + c.Param("field"),
+		})
+		return
+	}
+	if msg, ok := validationMessage(err); ok {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": msg,
+		})
+		return
+	}
+	if err != nil {
+		renderStoreError(c, err)
+		return
+	}
+
+	recordRevision(c, models.RevisionUpdate, &current, &reset)
+	sitesSaved()
+
+	c.Redirect(http.StatusFound, "/admin/sites/edit/"+id)
+}
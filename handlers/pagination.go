@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"ai-navigator/config"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultPageSize 没有配置 page_size 时每页显示的站点数量
+const defaultPageSize = 60
+
+// pageWindow 当前页前后各显示的页码数量，其余页码以省略号代替
+const pageWindow = 2
+
+// pagination 首页和搜索结果的分页信息
+type pagination struct {
+	Page       int
+	TotalPages int
+	Total      int
+	PrevURL    string
+	NextURL    string
+	Pages      []pageLink
+}
+
+// pageLink 分页导航中的一项，Gap 为 true 时表示省略的页码
+type pageLink struct {
+	Number  int
+	URL     string
+	Current bool
+	Gap     bool
+}
+
+func pageSize() int {
+	if config.AppConfig.PageSize > 0 {
+		return config.AppConfig.PageSize
+	}
+	return defaultPageSize
+}
+
+// paginate 根据请求的 page 参数计算当前页在 total 条结果中的范围 [start, end)，
+// 页码无效时显示第一页，超出范围时显示最后一页；翻页链接保留请求中的其它查询参数
+func paginate(c *gin.Context, total int) (p pagination, start, end int) {
+	size := pageSize()
+	p.Total = total
+	p.TotalPages = (total + size - 1) / size
+	if p.TotalPages == 0 {
+		p.TotalPages = 1
+	}
+
+	p.Page, _ = strconv.Atoi(c.Query("page"))
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.Page > p.TotalPages {
+		p.Page = p.TotalPages
+	}
+
+	start = (p.Page - 1) * size
+	end = min(start+size, total)
+
+	pageURL := func(n int) string {
+		u := *c.Request.URL
+		q := u.Query()
+		if n > 1 {
+			q.Set("page", strconv.Itoa(n))
+		} else {
+			q.Del("page")
+		}
+		u.RawQuery = q.Encode()
+		return u.RequestURI()
+	}
+	if p.Page > 1 {
+		p.PrevURL = pageURL(p.Page - 1)
+	}
+	if p.Page < p.TotalPages {
+		p.NextURL = pageURL(p.Page + 1)
+	}
+	for n := 1; n <= p.TotalPages; n++ {
+		if n != 1 && n != p.TotalPages && (n < p.Page-pageWindow || n > p.Page+pageWindow) {
+			if last := len(p.Pages) - 1; last < 0 || !p.Pages[last].Gap {
+				p.Pages = append(p.Pages, pageLink{Gap: true})
+			}
+			continue
+		}
+		p.Pages = append(p.Pages, pageLink{Number: n, URL: pageURL(n), Current: n == p.Page})
+	}
+	return p, start, end
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"ai-navigator/models"
+	"ai-navigator/store"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// emptyPlanRows 站点表单中在已有套餐之后留出的空白套餐行数
+const emptyPlanRows = 2
+
+// pricingOption 定价模式或计费周期下拉框的一项
+type pricingOption struct {
+	Value    string
+	Label    string
+	Selected bool
+}
+
+// pricingForm 站点表单中定价部分的数据
+type pricingForm struct {
+	Models   []pricingOption
+	Periods  []pricingOption
+	Plans    []models.PricingPlan
+	FreeTier string
+}
+
+// newPricingForm 根据站点的定价生成表单数据，已有套餐之后留出空白行用于新增套餐
+func newPricingForm(pricing *models.Pricing) pricingForm {
+	var form pricingForm
+	current := ""
+	if pricing != nil {
+		current = pricing.Model
+		form.Plans = append(form.Plans, pricing.Plans...)
+		form.FreeTier = pricing.FreeTier
+	}
+	for _, model := range models.PricingModels {
+		form.Models = append(form.Models, pricingOption{Value: model, Label: models.PricingModelLabel(model), Selected: model == current})
+	}
+	for _, period := range models.BillingPeriods {
+		form.Periods = append(form.Periods, pricingOption{Value: period, Label: models.BillingPeriodLabel(period)})
+	}
+	for range emptyPlanRows {
+		form.Plans = append(form.Plans, models.PricingPlan{})
+	}
+	return form
+}
+
+// pricingFromForm 从站点表单读取定价，没有选择定价模式也没有填写套餐时返回 nil
+// 套餐按行提交，名称为空的行忽略；价格和免费额度以外的字段不在表单中，由调用方通过 Track 沿用
+// 价格不是数字时返回校验错误，与保存时的校验错误一样在表单上提示
+func pricingFromForm(c *gin.Context) (*models.Pricing, error) {
+	names := c.PostFormArray("PlanName")
+	prices := c.PostFormArray("PlanPrice")
+	currencies := c.PostFormArray("PlanCurrency")
+	periods := c.PostFormArray("PlanPeriod")
+
+	field := func(values []string, i int) string {
+		if i < len(values) {
+			return strings.TrimSpace(values[i])
+		}
+		return ""
+	}
+
+	pricing := &models.Pricing{
+		Model:    c.PostForm("PricingModel"),
+		FreeTier: strings.TrimSpace(c.PostForm("FreeTier")),
+	}
+	var errs store.ValidationErrors
+	for i := range names {
+		name := field(names, i)
+		if name == "" {
+			continue
+		}
+		plan := models.PricingPlan{
+			Name:     name,
+			Currency: strings.ToUpper(field(currencies, i)),
+			Period:   field(periods, i),
+		}
+		if price := field(prices, i); price != "" {
+			v, err := strconv.ParseFloat(price, 64)
+			if err != nil {
+				errs = append(errs, store.ValidationError{
+					File:   "站点表单",
+					Index:  -1,
+					Field:  "pricing",
+					Reason: fmt.Sprintf("套餐 %s 的价格 %q 不是有效的数字", name, price),
+				})
+			}
+			plan.Price = v
+		}
+		pricing.Plans = append(pricing.Plans, plan)
+	}
+	if len(errs) > 0 {
+		return pricing, errs
+	}
+
+	if pricing.Model == "" && len(pricing.Plans) == 0 && pricing.FreeTier == "" {
+		return nil, nil
+	}
+	return pricing, nil
+}
+
+// today 返回记录价格变化使用的日期
+func today() string {
+	return time.Now().Format("2006-01-02")
+}
+
+// pricingFilter 首页和搜索结果中按定价筛选的一项
+type pricingFilter struct {
+	Label  string
+	URL    string
+	Active bool
+}
+
+// pricingFilters 返回按定价筛选的链接，保留当前的搜索词、分类和排序，切换筛选时回到第一页
+func pricingFilters(c *gin.Context, selected string) []pricingFilter {
+	link := func(model string) string {
+		q := c.Request.URL.Query()
+		q.Del("page")
+		if model == "" {
+			q.Del("pricing")
+		} else {
+			q.Set("pricing", model)
+		}
+		return (&url.URL{Path: "/search", RawQuery: q.Encode()}).RequestURI()
+	}
+
+	filters := []pricingFilter{{Label: "全部", URL: link(""), Active: selected == ""}}
+	for _, model := range models.PricingModels {
+		filters = append(filters, pricingFilter{
+			Label:  models.PricingModelLabel(model),
+			URL:    link(model),
+			Active: model == selected,
+		})
+	}
+	return filters
+}
+
+// matchPricing 判断站点是否符合定价筛选，pricing 为空时不筛选
+func matchPricing(site models.Site, pricing string) bool {
+	return pricing == "" || site.Pricing != nil && site.Pricing.Model == pricing
+}
@@ -33,13 +33,17 @@ func filterSites(sites []models.Site, query string, category string, sortBy stri
 	return filtered
 }
 
-func filterDisplaySites(displaySites []models.SiteDisplay, query string, category string, sortBy string) []models.SiteDisplay {
+// filterDisplaySites 按关键词、分类和定价模式筛选站点，categories 为属于所选分类（含子分类）的 Category 值，nil 表示不限分类
+func filterDisplaySites(displaySites []models.SiteDisplay, query string, categories map[string]bool, pricing string, sortBy string) []models.SiteDisplay {
 	var filtered []models.SiteDisplay
 	query = strings.ToLower(query)
 
 	for _, ds := range displaySites {
 		site := ds.Site
-		if category != "" && site.Category != category {
+		if categories != nil && !categories[site.Category] {
+			continue
+		}
+		if !matchPricing(site, pricing) {
 			continue
 		}
 
@@ -2,158 +2,147 @@ package handlers
 
 import (
 	"ai-navigator/models"
-	"ai-navigator/utils"
-	"encoding/json"
+	"ai-navigator/store"
 	"log"
-	"os"
 	"sync"
-
-	"github.com/fsnotify/fsnotify"
+	"sync/atomic"
+	"time"
 )
 
 var (
-	sites            []models.Site
-	sitesLock        sync.RWMutex
-	displaySites     []models.SiteDisplay
-	displaySitesLock sync.RWMutex
+	siteStore store.SiteStore
+	// loadMu 保证同一时间只有一次加载，快照的版本号依次递增
+	loadMu sync.Mutex
+	// loadRequested 请求加载的次数，loadedUpTo 最近一次加载开始时已有的请求数，受 loadMu 保护
+	// 加载开始前提交的请求都能读到对应的修改，同一时间的多个请求只需要加载一次
+	loadRequested atomic.Uint64
+	loadedUpTo    uint64
+
+	loadStatus     LoadStatus
+	loadStatusLock sync.RWMutex
 )
 
-func init() {
+// LoadStatus 最近一次数据加载的结果
+type LoadStatus struct {
+	// LastSuccess 最近一次成功加载的时间，当前提供服务的就是这份数据
+	LastSuccess time.Time
+	// LastAttempt 最近一次尝试加载的时间
+	LastAttempt time.Time
+	// Err 最近一次加载失败的原因，成功时为 nil
+	Err error
+}
+
+// InitStore 设置处理器使用的站点存储，加载数据并开始监听变更
+func InitStore(s store.SiteStore) {
+	siteStore = s
+	if publisher, ok := s.(store.Publisher); ok {
+		publisher.OnWrite(publishSites)
+	}
 	loadSites()
-	go watchFileChanges()
+
+	if err := siteStore.Watch(loadSites); err != nil {
+		log.Printf("监控数据变更失败: %v", err)
+	}
 }
 
+// loadSites 从存储重新加载站点并发布新的快照，数据无效时保留上一次成功加载的快照继续提供服务
+// 后台连续保存时，等待期间已被其它加载覆盖的请求直接返回
 func loadSites() {
-	aiData, err := os.ReadFile("./data/ai.json")
-	if err != nil {
-		log.Printf("读取 ai.json 文件失败: %v", err)
-		return
-	}
+	requested := loadRequested.Add(1)
+	loadMu.Lock()
+	defer loadMu.Unlock()
 
-	var aiSites []models.Site
-	if err := json.Unmarshal(aiData, &aiSites); err != nil {
-		log.Printf("解析 ai.json JSON 失败: %v", err)
+	if loadedUpTo >= requested {
 		return
 	}
+	loadedUpTo = loadRequested.Load()
 
-	customData, err := os.ReadFile("./data/custom.json")
-	var customSites []models.Site
+	loaded, err := siteStore.List()
+
+	loadStatusLock.Lock()
+	loadStatus.LastAttempt = time.Now()
+	loadStatus.Err = err
 	if err == nil {
-		if err := json.Unmarshal(customData, &customSites); err != nil {
-			log.Printf("解析 custom.json JSON 失败: %v", err)
-		}
+		loadStatus.LastSuccess = loadStatus.LastAttempt
 	}
+	loadStatusLock.Unlock()
 
-	mergedSites := mergeSites(aiSites, customSites)
-
-	sitesLock.Lock()
-	sites = mergedSites
-	sitesLock.Unlock()
+	if err != nil {
+		log.Printf("加载站点数据失败，继续使用上一次加载的数据: %v", err)
+		return
+	}
 
-	precomputeDisplaySites(mergedSites)
+	prev := currentCatalog()
+	publishCatalog(newCatalog(prev.Version+1, loaded, loadCategories(prev), loadTags(prev), prev))
 }
 
-func precomputeDisplaySites(sites []models.Site) {
-	display := make([]models.SiteDisplay, len(sites))
-	for i, site := range sites {
-		display[i] = models.SiteDisplay{
-			Site:     site,
-			Color:    utils.GenerateColorFromName(site.Name),
-			Initials: utils.GetInitialsFromName(site.Name),
-		}
-	}
+// publishSites 用存储写入后的站点列表直接发布新快照，分类和标签沿用当前快照，不再从存储重新读取
+func publishSites(sites []models.Site) {
+	loadMu.Lock()
+	defer loadMu.Unlock()
 
-	displaySitesLock.Lock()
-	displaySites = display
-	displaySitesLock.Unlock()
-}
+	loadStatusLock.Lock()
+	loadStatus.LastAttempt = time.Now()
+	loadStatus.LastSuccess = loadStatus.LastAttempt
+	loadStatus.Err = nil
+	loadStatusLock.Unlock()
 
-func getDisplaySites() []models.SiteDisplay {
-	displaySitesLock.RLock()
-	defer displaySitesLock.RUnlock()
-	return displaySites
+	prev := currentCatalog()
+	publishCatalog(newCatalog(prev.Version+1, sites, prev.Categories.defined, prev.Tags.Tags, prev))
 }
 
-func watchFileChanges() {
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer watcher.Close()
-
-	err = watcher.Add("./data/ai.json")
-	if err != nil {
-		log.Printf("监控 ai.json 文件失败: %v", err)
+// sitesSaved 站点写入后调用，存储已在写入时发布新快照的不再重新加载
+func sitesSaved() {
+	if _, ok := siteStore.(store.Publisher); !ok {
+		loadSites()
 	}
+}
 
-	err = watcher.Add("./data/custom.json")
-	if err != nil {
-		log.Printf("监控 custom.json 文件失败: %v", err)
-	}
+// publishCatalog 发布新快照，调用方需持有 loadMu
+func publishCatalog(next *catalog) {
+	catalogPtr.Store(next)
 
-	for {
-		select {
-		case event, ok := <-watcher.Events:
-			if !ok {
-				return
-			}
-			if event.Has(fsnotify.Write) {
-				log.Println("检测到文件变更，重新加载数据")
-				loadSites()
-			}
-		case err, ok := <-watcher.Errors:
-			if !ok {
-				return
-			}
-			log.Printf("监控错误: %v", err)
+	// 在后台查找重复站点，不推迟新快照的发布；期间已被替换的快照不再计算
+	go func() {
+		if currentCatalog() != next {
+			return
 		}
-	}
-}
-
-func getUniqueCategories(sites []models.Site) map[string]bool {
-	categories := make(map[string]bool)
-	for _, site := range sites {
-		if site.Category != "" {
-			categories[site.Category] = true
+		if n := len(next.Duplicates()); n > 0 {
+			log.Printf("发现 %d 对可能重复的站点，可在后台「重复站点」页面查看和合并", n)
 		}
-	}
-	return categories
+	}()
 }
 
-func mergeSites(aiSites, customSites []models.Site) []models.Site {
-	customSiteMap := make(map[string]models.Site)
-	for _, site := range customSites {
-		customSiteMap[site.Name] = site
-	}
-
-	var result []models.Site
-
-	for _, site := range customSites {
-		if !site.Deleted {
-			result = append(result, site)
-		}
+// loadCategories 读取分类表，读取失败时沿用上一份快照中的分类，存储不支持分类时只使用站点中出现的分类
+func loadCategories(prev *catalog) []models.Category {
+	categoryStore, ok := siteStore.(store.CategoryStore)
+	if !ok {
+		return nil
 	}
-
-	for _, site := range aiSites {
-		if _, exists := customSiteMap[site.Name]; !exists {
-			result = append(result, site)
-		}
+	categories, err := categoryStore.ListCategories()
+	if err != nil {
+		log.Printf("加载分类数据失败，继续使用上一次加载的分类: %v", err)
+		return prev.Categories.defined
 	}
-
-	return result
+	return categories
 }
 
-func saveSites() {
-	sitesLock.RLock()
-	data, err := json.MarshalIndent(sites, "", "    ")
-	sitesLock.RUnlock()
-
+// loadTags 读取标签注册表，读取失败时沿用上一份快照中的注册表，存储不支持时注册表为空
+func loadTags(prev *catalog) []models.Tag {
+	tagStore, ok := siteStore.(store.TagStore)
+	if !ok {
+		return nil
+	}
+	tags, err := tagStore.ListTags()
 	if err != nil {
-		log.Printf("序列化JSON失败: %v", err)
-		return
+		log.Printf("加载标签数据失败，继续使用上一次加载的标签: %v", err)
+		return prev.Tags.Tags
 	}
+	return tags
+}
 
-	if err := os.WriteFile("./data/custom.json", data, 0644); err != nil {
-		log.Printf("写入 custom.json 文件失败: %v", err)
-	}
+func getLoadStatus() LoadStatus {
+	loadStatusLock.RLock()
+	defer loadStatusLock.RUnlock()
+	return loadStatus
 }
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"ai-navigator/models"
+	"ai-navigator/store"
+	"ai-navigator/utils"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminTagsHandler 标签管理页面：列出全部标签的使用次数，提供登记、编辑标签和重命名、合并标签的表单
+// 带 edit 参数时编辑对应的标签，带 name 参数时以站点中未登记的标签预填新增表单
+func AdminTagsHandler(c *gin.Context) {
+	tag := models.Tag{Name: c.Query("name")}
+	editing := false
+	if name := c.Query("edit"); name != "" {
+		registered, ok := currentCatalog().Tags.lookup(name)
+		if !ok {
+			c.HTML(http.StatusNotFound, "error.html", gin.H{
+				"error": "标签不存在",
+			})
+			return
+		}
+		tag, editing = registered, true
+	}
+
+	renderTags(c, http.StatusOK, tag, editing, gin.H{
+		"saved":   c.Query("saved"),
+		"deleted": c.Query("deleted"),
+		"renamed": c.Query("renamed"),
+	})
+}
+
+// AdminAddTagHandler 将标签登记到注册表，站点中与新标签的别名相同的标签在下一次加载时显示为规范名称
+func AdminAddTagHandler(c *gin.Context) {
+	tagStore, ok := tagStoreOrError(c)
+	if !ok {
+		return
+	}
+
+	tag := tagFromForm(c)
+	if err := tagStore.CreateTag(tag); err != nil {
+		renderTagError(c, tag, false, err)
+		return
+	}
+
+	commitChange(c, "登记标签 "+tag.Name)
+	loadSites()
+	c.Redirect(http.StatusFound, "/admin/tags?saved="+url.QueryEscape(tag.Name))
+}
+
+// AdminEditTagHandler 保存标签的别名、颜色和说明，名称改变时按重命名处理，同时修改使用该标签的站点
+// 标签名称可能包含 / 等字符，原名称通过表单的 Original 字段提交而不放在路径中
+func AdminEditTagHandler(c *gin.Context) {
+	tagStore, ok := tagStoreOrError(c)
+	if !ok {
+		return
+	}
+
+	name := c.PostForm("Original")
+	registered, ok := currentCatalog().Tags.lookup(name)
+	if !ok {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "标签不存在",
+		})
+		return
+	}
+
+	tag := tagFromForm(c)
+	renamed := tag.Name != registered.Name
+	if renamed {
+		// 旧名称成为别名，之后导入或填写旧名称的标签仍会换成新名称
+		tag.Aliases = append(tag.Aliases, registered.Name)
+	}
+	if err := tagStore.UpdateTag(registered.Name, tag); err != nil {
+		renderTagError(c, tag, true, err)
+		return
+	}
+
+	if !renamed {
+		commitChange(c, "编辑标签 "+tag.Name)
+		loadSites()
+		c.Redirect(http.StatusFound, "/admin/tags?saved="+url.QueryEscape(tag.Name))
+		return
+	}
+	if err := retagSites(c, registered, fmt.Sprintf("重命名标签 %s 为 %s", registered.Name, tag.Name)); err != nil {
+		renderTagError(c, tag, true, err)
+		return
+	}
+	c.Redirect(http.StatusFound, "/admin/tags?renamed="+url.QueryEscape(tag.Name))
+}
+
+// AdminDeleteTagHandler 从注册表中删除标签，站点中的标签保持不变
+func AdminDeleteTagHandler(c *gin.Context) {
+	tagStore, ok := tagStoreOrError(c)
+	if !ok {
+		return
+	}
+
+	name := c.PostForm("Name")
+	if err := tagStore.DeleteTag(name); err != nil {
+		renderTagError(c, models.Tag{}, false, err)
+		return
+	}
+
+	commitChange(c, "删除标签 "+name)
+	loadSites()
+	c.Redirect(http.StatusFound, "/admin/tags?deleted="+url.QueryEscape(name))
+}
+
+// AdminRenameTagHandler 将全部站点中的标签 From 改为 To：To 已经存在时即为合并
+// 注册表中 From 的名称和别名成为 To 的别名，From 没有登记时自动登记 To 并以 From 为别名
+func AdminRenameTagHandler(c *gin.Context) {
+	tagStore, ok := tagStoreOrError(c)
+	if !ok {
+		return
+	}
+
+	from, to := utils.CleanTag(c.PostForm("From")), utils.CleanTag(c.PostForm("To"))
+	if utils.TagKey(from) == "" || utils.TagKey(to) == "" {
+		renderTags(c, http.StatusOK, models.Tag{}, false, gin.H{
+			"error": "请填写原标签和新标签",
+		})
+		return
+	}
+	if strings.ContainsAny(to, ",，") {
+		renderTags(c, http.StatusOK, models.Tag{}, false, gin.H{
+			"error": "标签名称不能包含逗号",
+		})
+		return
+	}
+
+	registry := currentCatalog().Tags
+	source, sourceRegistered := registry.lookup(from)
+	target, targetRegistered := registry.lookup(to)
+	if !sourceRegistered {
+		// 原标签没有登记时以站点中的写法为准
+		name, ok := usedTagName(from)
+		if !ok {
+			renderTags(c, http.StatusOK, models.Tag{}, false, gin.H{
+				"error": fmt.Sprintf("没有站点使用标签「%s」", from),
+			})
+			return
+		}
+		source = models.Tag{Name: name}
+	}
+	if targetRegistered && target.Name == source.Name {
+		if to == source.Name {
+			renderTags(c, http.StatusOK, models.Tag{}, false, gin.H{
+				"error": fmt.Sprintf("「%s」和「%s」已经是同一个标签", from, to),
+			})
+			return
+		}
+		// 新标签是原标签的别名或只是写法不同，按重命名处理
+		targetRegistered = false
+	}
+
+	_, targetUsed := usedTagName(to)
+	merging := targetRegistered || targetUsed && utils.TagKey(to) != utils.TagKey(source.Name)
+	var err error
+	switch {
+	case sourceRegistered && targetRegistered:
+		err = tagStore.MergeTags(source.Name, target.Name)
+	case sourceRegistered:
+		renamed := source
+		renamed.Name = to
+		renamed.Aliases = append(slices.Clone(source.Aliases), source.Name)
+		err = tagStore.UpdateTag(source.Name, renamed)
+	case targetRegistered:
+		target.Aliases = append(slices.Clone(target.Aliases), source.Name)
+		err = tagStore.UpdateTag(target.Name, target)
+	default:
+		err = tagStore.CreateTag(models.Tag{Name: to, Aliases: []string{source.Name}})
+	}
+	if err != nil {
+		renderTagError(c, models.Tag{}, false, err)
+		return
+	}
+
+	if targetRegistered {
+		to = target.Name
+	}
+	subject := fmt.Sprintf("重命名标签 %s 为 %s", source.Name, to)
+	if merging {
+		subject = fmt.Sprintf("合并标签 %s 到 %s", source.Name, to)
+	}
+	if err := retagSites(c, source, subject); err != nil {
+		renderTagError(c, models.Tag{}, false, err)
+		return
+	}
+	c.Redirect(http.StatusFound, "/admin/tags?renamed="+url.QueryEscape(to))
+}
+
+// retagSites 注册表修改后，将使用 old（名称或任一别名）的站点的标签换成新的规范名称
+// 在一次写入中保存全部站点，为每个站点记录修订，并以 subject 为首行只产生一次提交
+func retagSites(c *gin.Context, old models.Tag, subject string) error {
+	tagStore := siteStore.(store.TagStore)
+	tags, err := tagStore.ListTags()
+	if err != nil {
+		return err
+	}
+	registry := newTagRegistry(tags)
+
+	keys := []string{utils.TagKey(old.Name)}
+	for _, alias := range old.Aliases {
+		keys = append(keys, utils.TagKey(alias))
+	}
+
+	// 使用存储中的站点而不是快照，快照中的标签已按旧的注册表换过名称
+	sites, err := siteStore.List()
+	if err != nil {
+		return err
+	}
+	var batch, before []models.Site
+	for _, site := range sites {
+		if !slices.ContainsFunc(site.Tags, func(tag string) bool {
+			return slices.Contains(keys, utils.TagKey(tag))
+		}) {
+			continue
+		}
+		updated := site
+		updated.Tags = registry.normalize(site.Tags)
+		if slices.Equal(updated.Tags, site.Tags) {
+			continue
+		}
+		batch = append(batch, updated)
+		before = append(before, site)
+	}
+
+	if len(batch) == 0 {
+		commitChange(c, subject)
+		loadSites()
+		return nil
+	}
+
+	saved, err := siteStore.SaveAll(batch)
+	if err != nil {
+		return err
+	}
+	revs := make([]models.Revision, len(saved))
+	for i := range saved {
+		revs[i] = saveRevision(c, models.RevisionUpdate, &before[i], &saved[i])
+	}
+	commitChange(c, batchCommitMessage(fmt.Sprintf("%s：修改 %d 个站点", subject, len(saved)), revs))
+	loadSites()
+	return nil
+}
+
+// usedTagName 返回快照中站点使用的与 tag 相同（按 utils.TagKey 比较）的标签写法，没有站点使用时返回 false
+func usedTagName(tag string) (string, bool) {
+	key := utils.TagKey(tag)
+	for _, usage := range currentCatalog().TagUsage() {
+		if usage.Count > 0 && utils.TagKey(usage.Name) == key {
+			return usage.Name, true
+		}
+	}
+	return "", false
+}
+
+// renderTags 渲染标签管理页面，tag 为表单中显示的标签，editing 表示表单用于编辑已登记的标签
+func renderTags(c *gin.Context, status int, tag models.Tag, editing bool, data gin.H) {
+	_, supported := siteStore.(store.TagStore)
+
+	data["tags"] = currentCatalog().TagUsage()
+	data["tag"] = tag
+	data["aliasesString"] = strings.Join(tag.Aliases, ", ")
+	data["editing"] = editing
+	data["tagSuggestions"] = tagSuggestions()
+	data["unsupported"] = !supported
+	data["isAdmin"] = true
+	c.HTML(status, "admin-tags.html", data)
+}
+
+// renderTagError 保存标签失败时重新显示表单和错误原因
+func renderTagError(c *gin.Context, tag models.Tag, editing bool, err error) {
+	var msg string
+	switch {
+	case errors.Is(err, store.ErrTagExists):
+		msg = "保存失败：名称为 " + tag.Name + " 的标签已存在"
+	case errors.Is(err, store.ErrTagNotFound):
+		msg = "标签不存在"
+	default:
+		var ok bool
+		if msg, ok = validationMessage(err); !ok {
+			log.Printf("保存标签失败: %v", err)
+			msg = "保存标签数据失败"
+		}
+	}
+	renderTags(c, http.StatusOK, tag, editing, gin.H{"error": msg})
+}
+
+// tagStoreOrError 返回支持标签注册表的存储，不支持时渲染错误页面
+func tagStoreOrError(c *gin.Context) (store.TagStore, bool) {
+	tagStore, ok := siteStore.(store.TagStore)
+	if !ok {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": "当前存储不支持标签管理",
+		})
+	}
+	return tagStore, ok
+}
+
+// tagFromForm 从标签表单读取字段，别名以逗号分隔
+func tagFromForm(c *gin.Context) models.Tag {
+	return models.Tag{
+		Name:        utils.CleanTag(c.PostForm("Name")),
+		Aliases:     utils.SplitTags(c.PostForm("Aliases")),
+		Color:       strings.TrimSpace(c.PostForm("Color")),
+		Description: strings.TrimSpace(c.PostForm("Description")),
+	}
+}
+
+// tagSuggestions 站点表单中自动补全的标签：已登记的标签和站点中使用过的标签，按使用次数排列
+func tagSuggestions() []string {
+	usage := currentCatalog().TagUsage()
+	names := make([]string, len(usage))
+	for i, tag := range usage {
+		names[i] = tag.Name
+	}
+	return names
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"ai-navigator/models"
+	"ai-navigator/utils"
+	"slices"
+	"sort"
+)
+
+// tagRegistry 快照中的标签注册表，按名称和别名查找规范的标签
+type tagRegistry struct {
+	Tags []models.Tag
+	// byKey 名称和别名的 utils.TagKey 对应的标签下标
+	byKey map[string]int
+}
+
+func newTagRegistry(tags []models.Tag) *tagRegistry {
+	r := &tagRegistry{Tags: tags, byKey: make(map[string]int)}
+	for i, tag := range tags {
+		r.byKey[utils.TagKey(tag.Name)] = i
+		for _, alias := range tag.Aliases {
+			r.byKey[utils.TagKey(alias)] = i
+		}
+	}
+	return r
+}
+
+// lookup 按名称或别名查找注册表中的标签
+func (r *tagRegistry) lookup(tag string) (models.Tag, bool) {
+	i, ok := r.byKey[utils.TagKey(tag)]
+	if !ok {
+		return models.Tag{}, false
+	}
+	return r.Tags[i], true
+}
+
+// canonical 返回标签的规范写法：注册表中有的返回规范名称，否则只整理空白
+func (r *tagRegistry) canonical(tag string) string {
+	if registered, ok := r.lookup(tag); ok {
+		return registered.Name
+	}
+	return utils.CleanTag(tag)
+}
+
+// normalize 将站点的标签替换为规范写法，去掉空标签和重复的标签
+// 没有需要修改的标签时直接返回 tags，加载大量站点时不产生额外的内存分配
+func (r *tagRegistry) normalize(tags []string) []string {
+	// 站点的标签通常只有几个，用切片记录已出现的标签比 map 更省
+	keys := make([]string, 0, len(tags))
+	changed := false
+	for _, tag := range tags {
+		name := r.canonical(tag)
+		key := utils.TagKey(name)
+		if name != tag || key == "" || slices.Contains(keys, key) {
+			changed = true
+			break
+		}
+		keys = append(keys, key)
+	}
+	if !changed {
+		return tags
+	}
+
+	result := make([]string, 0, len(tags))
+	keys = keys[:0]
+	for _, tag := range tags {
+		name := r.canonical(tag)
+		key := utils.TagKey(name)
+		if key == "" || slices.Contains(keys, key) {
+			continue
+		}
+		keys = append(keys, key)
+		result = append(result, name)
+	}
+	return result
+}
+
+// badges 返回站点卡片中显示的标签，注册表中的标签带有颜色和说明
+func (r *tagRegistry) badges(tags []string) []models.TagBadge {
+	if len(tags) == 0 {
+		return nil
+	}
+	badges := make([]models.TagBadge, len(tags))
+	for i, tag := range tags {
+		badges[i] = models.TagBadge{Name: tag}
+		if registered, ok := r.lookup(tag); ok {
+			badges[i].Color, badges[i].Description = registered.Color, registered.Description
+		}
+	}
+	return badges
+}
+
+// tagUsage 标签的使用情况，Registered 为 false 表示没有登记到注册表
+type tagUsage struct {
+	models.Tag
+	Registered bool
+	Count      int
+}
+
+// countTags 统计站点中每个标签的使用次数，注册表中没有站点使用的标签数量为 0
+// 按使用次数从多到少排列，次数相同时按名称排列
+func countTags(registry *tagRegistry, sites []models.Site) []tagUsage {
+	usage := make([]tagUsage, len(registry.Tags))
+	index := make(map[string]int, len(registry.Tags))
+	for i, tag := range registry.Tags {
+		usage[i] = tagUsage{Tag: tag, Registered: true}
+		index[utils.TagKey(tag.Name)] = i
+	}
+	for _, site := range sites {
+		for _, tag := range site.Tags {
+			key := utils.TagKey(tag)
+			i, ok := index[key]
+			if !ok {
+				i = len(usage)
+				index[key] = i
+				usage = append(usage, tagUsage{Tag: models.Tag{Name: tag}})
+			}
+			usage[i].Count++
+		}
+	}
+	sort.SliceStable(usage, func(i, j int) bool {
+		if usage[i].Count != usage[j].Count {
+			return usage[i].Count > usage[j].Count
+		}
+		return usage[i].Name < usage[j].Name
+	})
+	return usage
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"ai-navigator/models"
+	"ai-navigator/store"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// trashPurgeInterval 检查回收站中过期站点的间隔
+const trashPurgeInterval = time.Hour
+
+// trashPurgeActor 自动清理回收站时记录的操作人
+const trashPurgeActor = "自动清理"
+
+// trashDays 回收站保留天数，0 表示不自动清理
+var trashDays int
+
+// trashItem 回收站页面中的一个站点
+type trashItem struct {
+	models.Site
+	// DeletedTime 删除时间，旧数据没有记录时为零值
+	DeletedTime time.Time
+	// PurgeAt 将被自动清理的时间，未开启自动清理或没有删除时间时为零值
+	PurgeAt time.Time
+}
+
+func AdminTrashHandler(c *gin.Context) {
+	trash, ok := siteStore.(store.Trash)
+	if !ok {
+		c.HTML(http.StatusOK, "admin-trash.html", gin.H{
+			"unsupported": true,
+			"isAdmin":     true,
+		})
+		return
+	}
+
+	deleted, err := trash.ListDeleted()
+	if err != nil {
+		renderStoreError(c, err)
+		return
+	}
+
+	items := make([]trashItem, len(deleted))
+	for i, site := range deleted {
+		items[i] = trashItem{Site: site}
+		if t, err := time.Parse(time.RFC3339, site.DeletedAt); err == nil {
+			items[i].DeletedTime = t
+			if trashDays > 0 {
+				items[i].PurgeAt = t.AddDate(0, 0, trashDays)
+			}
+		}
+	}
+
+	c.HTML(http.StatusOK, "admin-trash.html", gin.H{
+		"sites":     items,
+		"trashDays": trashDays,
+		"restored":  c.Query("restored"),
+		"purged":    c.Query("purged"),
+		"isAdmin":   true,
+	})
+}
+
+// AdminRestoreDeletedHandler 将回收站中的站点恢复为删除前的状态
+func AdminRestoreDeletedHandler(c *gin.Context) {
+	trash, ok := siteStore.(store.Trash)
+	if !ok {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": "当前存储不支持回收站",
+		})
+		return
+	}
+
+	id := c.Param("id")
+	before, err := findDeleted(trash, id)
+	if err != nil {
+		renderStoreError(c, err)
+		return
+	}
+
+	restored, err := trash.RestoreDeleted(id)
+	if err != nil {
+		if msg, ok := validationMessage(err); ok {
+			c.HTML(http.StatusBadRequest, "error.html", gin.H{
+				"error": "站点数据无效，无法恢复：" + msg,
+			})
+			return
+		}
+		renderStoreError(c, err)
+		return
+	}
+
+	recordRevision(c, models.RevisionRestore, &before, &restored)
+	sitesSaved()
+
+	c.Redirect(http.StatusFound, "/admin/trash?restored="+url.QueryEscape(restored.Name))
+}
+
+// AdminPurgeHandler 彻底删除回收站中的站点，之后只能从修订历史中恢复
+func AdminPurgeHandler(c *gin.Context) {
+	trash, ok := siteStore.(store.Trash)
+	if !ok {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": "当前存储不支持回收站",
+		})
+		return
+	}
+
+	purged, err := trash.Purge(c.Param("id"))
+	if err != nil {
+		renderStoreError(c, err)
+		return
+	}
+
+	after := purgedSnapshot(purged)
+	recordRevision(c, models.RevisionPurge, &purged, &after)
+
+	c.Redirect(http.StatusFound, "/admin/trash?purged="+url.QueryEscape(purged.Name))
+}
+
+// StartTrashPurge 每小时彻底删除在回收站中超过 days 天的站点，days 不大于 0 时不自动清理
+func StartTrashPurge(days int) {
+	trashDays = days
+	if days <= 0 {
+		return
+	}
+	if _, ok := siteStore.(store.Trash); !ok {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+		for {
+			purgeExpiredTrash(days)
+			<-ticker.C
+		}
+	}()
+}
+
+// purgeExpiredTrash 彻底删除过期的站点，为每个站点记录修订，启用 git 提交时只产生一次提交
+func purgeExpiredTrash(days int) {
+	trash := siteStore.(store.Trash)
+	purged, err := trash.PurgeDeletedBefore(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		log.Printf("自动清理回收站失败: %v", err)
+		return
+	}
+	if len(purged) == 0 {
+		return
+	}
+
+	revs := make([]models.Revision, len(purged))
+	for i := range purged {
+		after := purgedSnapshot(purged[i])
+		revs[i] = saveRevisionAs(trashPurgeActor, models.RevisionPurge, &purged[i], &after)
+	}
+	subject := fmt.Sprintf("自动清理回收站：彻底删除 %d 个超过 %d 天的站点", len(purged), days)
+	commitChangeAs(trashPurgeActor, batchCommitMessage(subject, revs))
+	log.Printf("已从回收站彻底删除 %d 个超过 %d 天的站点", len(purged), days)
+}
+
+// findDeleted 在回收站中查找站点
+func findDeleted(trash store.Trash, id string) (models.Site, error) {
+	deleted, err := trash.ListDeleted()
+	if err != nil {
+		return models.Site{}, err
+	}
+	for _, site := range deleted {
+		if site.ID == id {
+			return site, nil
+		}
+	}
+	return models.Site{}, store.ErrNotFound
+}
+
+// purgedSnapshot 彻底删除后的站点状态，只保留 ID 和删除标记
+func purgedSnapshot(site models.Site) models.Site {
+	return models.Site{ID: site.ID, Deleted: true}
+}
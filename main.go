@@ -5,7 +5,15 @@ import (
 	"ai-navigator/config"
 	"ai-navigator/handlers"
 	"ai-navigator/middleware"
+	"ai-navigator/store"
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/cookie"
@@ -18,23 +26,51 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// Subcommands
+	if len(os.Args) > 1 && os.Args[1] == "awesome" {
+		os.Exit(runAwesome(os.Args[2:]))
+	}
+
+	// Open site storage
+	siteStore, err := store.Open(config.AppConfig.Storage)
+	if err != nil {
+		log.Fatalf("Failed to open site store: %v", err)
+	}
+	defer siteStore.Close()
+	handlers.InitStore(siteStore)
+	handlers.StartTrashPurge(config.AppConfig.Storage.TrashDays)
+
 	// Create a new Gin router with default middleware
 	r := gin.Default()
 
 	// Setup session
-	store := cookie.NewStore([]byte(config.AppConfig.Session.Secret))
-	r.Use(sessions.Sessions("admin_session", store))
+	sessionStore := cookie.NewStore([]byte(config.AppConfig.Session.Secret))
+	r.Use(sessions.Sessions("admin_session", sessionStore))
 
 	// Load HTML templates - explicitly list files to avoid directory issues
 	r.LoadHTMLFiles(
 		"templates/index.html",
 		"templates/error.html",
+		"templates/site.html",
 		"templates/layout.html",
 		"templates/admin/admin-login.html",
 		"templates/admin/admin-index.html",
 		"templates/admin/admin-sites.html",
 		"templates/admin/admin-add-site.html",
 		"templates/admin/admin-edit-site.html",
+		"templates/admin/admin-backups.html",
+		"templates/admin/admin-site-history.html",
+		"templates/admin/admin-commits.html",
+		"templates/admin/admin-import-bookmarks.html",
+		"templates/admin/admin-import-csv.html",
+		"templates/admin/admin-import-webstack.html",
+		"templates/admin/admin-trash.html",
+		"templates/admin/admin-conflicts.html",
+		"templates/admin/admin-conflict.html",
+		"templates/admin/admin-duplicates.html",
+		"templates/admin/admin-edit-conflict.html",
+		"templates/admin/admin-categories.html",
+		"templates/admin/admin-tags.html",
 	)
 
 	// Serve static files
@@ -46,6 +82,8 @@ func main() {
 	// Frontend routes
 	r.GET("/", handlers.HomeHandler)
 	r.GET("/search", handlers.SearchHandler)
+	r.GET("/site/:id", handlers.SiteDetailHandler)
+	r.GET("/bookmarks.html", handlers.BookmarksHandler)
 
 	// Admin routes
 	admin := r.Group("/admin")
@@ -65,7 +103,42 @@ func main() {
 			adminAuth.POST("/sites/add", handlers.AdminAddSitePostHandler)
 			adminAuth.GET("/sites/edit/:id", handlers.AdminEditSiteHandler)
 			adminAuth.POST("/sites/edit/:id", handlers.AdminEditSitePostHandler)
+			adminAuth.POST("/sites/reset/:id/:field", handlers.AdminResetSiteFieldHandler)
 			adminAuth.GET("/sites/delete/:id", handlers.AdminDeleteSiteHandler)
+			adminAuth.GET("/sites/history/:id", handlers.AdminSiteHistoryHandler)
+			adminAuth.POST("/sites/history/:id/restore/:rev", handlers.AdminRestoreRevisionHandler)
+			adminAuth.GET("/categories", handlers.AdminCategoriesHandler)
+			adminAuth.POST("/categories/add", handlers.AdminAddCategoryHandler)
+			adminAuth.POST("/categories/edit/:slug", handlers.AdminEditCategoryHandler)
+			adminAuth.POST("/categories/delete/:slug", handlers.AdminDeleteCategoryHandler)
+			adminAuth.GET("/tags", handlers.AdminTagsHandler)
+			adminAuth.POST("/tags/add", handlers.AdminAddTagHandler)
+			adminAuth.POST("/tags/edit", handlers.AdminEditTagHandler)
+			adminAuth.POST("/tags/delete", handlers.AdminDeleteTagHandler)
+			adminAuth.POST("/tags/rename", handlers.AdminRenameTagHandler)
+			adminAuth.GET("/export", handlers.AdminExportHandler)
+			adminAuth.GET("/export/webstack", handlers.AdminExportWebStackHandler)
+			adminAuth.GET("/export/awesome", handlers.AdminExportAwesomeHandler)
+			adminAuth.GET("/import/bookmarks", handlers.AdminImportBookmarksHandler)
+			adminAuth.POST("/import/bookmarks", handlers.AdminImportBookmarksPostHandler)
+			adminAuth.POST("/import/bookmarks/confirm", handlers.AdminImportBookmarksConfirmHandler)
+			adminAuth.GET("/import/csv", handlers.AdminImportCSVHandler)
+			adminAuth.POST("/import/csv", handlers.AdminImportCSVPostHandler)
+			adminAuth.POST("/import/csv/confirm", handlers.AdminImportCSVConfirmHandler)
+			adminAuth.GET("/import/webstack", handlers.AdminImportWebStackHandler)
+			adminAuth.POST("/import/webstack", handlers.AdminImportWebStackPostHandler)
+			adminAuth.POST("/import/webstack/confirm", handlers.AdminImportWebStackConfirmHandler)
+			adminAuth.GET("/backups", handlers.AdminBackupsHandler)
+			adminAuth.POST("/backups/restore/:name", handlers.AdminRestoreBackupHandler)
+			adminAuth.GET("/commits", handlers.AdminCommitLogHandler)
+			adminAuth.GET("/trash", handlers.AdminTrashHandler)
+			adminAuth.POST("/trash/restore/:id", handlers.AdminRestoreDeletedHandler)
+			adminAuth.POST("/trash/purge/:id", handlers.AdminPurgeHandler)
+			adminAuth.GET("/conflicts", handlers.AdminConflictsHandler)
+			adminAuth.GET("/conflicts/:id", handlers.AdminConflictHandler)
+			adminAuth.POST("/conflicts/:id", handlers.AdminResolveConflictPostHandler)
+			adminAuth.GET("/duplicates", handlers.AdminDuplicatesHandler)
+			adminAuth.POST("/duplicates/merge", handlers.AdminMergeDuplicatesHandler)
 		}
 	}
 
@@ -74,6 +147,25 @@ func main() {
 	if port == "" {
 		port = "8080"
 	}
-	log.Printf("Server starting on http://localhost:%s", port)
-	r.Run(":" + port)
+	srv := &http.Server{Addr: ":" + port, Handler: r}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		log.Printf("Server starting on http://localhost:%s", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	log.Println("正在关闭服务，等待进行中的请求和未写入的修改完成")
+
+	// 先停止接收请求，处理中的保存完成后再关闭存储，排队的修改在 Close 中写入
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("关闭服务失败: %v", err)
+	}
 }
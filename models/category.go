@@ -0,0 +1,49 @@
+package models
+
+// Category 站点分类，站点的 Category 字段保存分类的标识
+// 旧数据中的站点直接以分类名称作为 Category，查找分类时标识和名称都可以匹配
+type Category struct {
+	Slug string `json:"slug" yaml:"slug"`
+	Name string `json:"name" yaml:"name"`
+	// Icon 分类图标，可以是 emoji 或者以 / 开头的站内路径、完整的图片网址
+	Icon        string `json:"icon,omitempty" yaml:"icon,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Parent 上级分类的标识，为空表示顶级分类
+	Parent string `json:"parent,omitempty" yaml:"parent,omitempty"`
+	// Order 同级分类的排序，数值小的在前，相同时按名称排序
+	Order int `json:"order,omitempty" yaml:"order,omitempty"`
+}
+
+// FindCategory 按标识或名称查找站点 Category 字段对应的分类，标识优先
+func FindCategory(categories []Category, value string) (Category, bool) {
+	if value == "" {
+		return Category{}, false
+	}
+	for _, category := range categories {
+		if category.Slug == value {
+			return category, true
+		}
+	}
+	for _, category := range categories {
+		if category.Name == value {
+			return category, true
+		}
+	}
+	return Category{}, false
+}
+
+// WithCategoryNames 返回 Category 替换为分类名称的站点副本，用于导出书签、awesome 列表等给人看的格式
+// 没有对应分类的站点保留原值
+func WithCategoryNames(sites []Site, categories []Category) []Site {
+	if len(categories) == 0 {
+		return sites
+	}
+	result := make([]Site, len(sites))
+	for i, site := range sites {
+		if category, ok := FindCategory(categories, site.Category); ok {
+			site.Category = category.Name
+		}
+		result[i] = site
+	}
+	return result
+}
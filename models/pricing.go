@@ -0,0 +1,219 @@
+package models
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// 站点的定价模式
+const (
+	PricingFree       = "free"        // 完全免费
+	PricingFreemium   = "freemium"    // 基础功能免费，高级功能付费
+	PricingPaid       = "paid"        // 只能付费使用，可能有试用期
+	PricingOpenSource = "open-source" // 开源，可以自行部署
+)
+
+// PricingModels 全部定价模式，按展示顺序排列
+var PricingModels = []string{PricingFree, PricingFreemium, PricingPaid, PricingOpenSource}
+
+// 套餐的计费周期
+const (
+	BillingMonthly = "month"
+	BillingYearly  = "year"
+	BillingOnce    = "once"  // 一次性买断
+	BillingUsage   = "usage" // 按量计费，价格为单位用量的价格
+)
+
+// BillingPeriods 全部计费周期，按展示顺序排列
+var BillingPeriods = []string{BillingMonthly, BillingYearly, BillingOnce, BillingUsage}
+
+// PricingModelLabel 返回定价模式的中文名称，未知的模式原样返回
+func PricingModelLabel(model string) string {
+	switch model {
+	case PricingFree:
+		return "免费"
+	case PricingFreemium:
+		return "免费增值"
+	case PricingPaid:
+		return "付费"
+	case PricingOpenSource:
+		return "开源"
+	}
+	return model
+}
+
+// BillingPeriodLabel 返回计费周期的中文名称，未知的周期原样返回
+func BillingPeriodLabel(period string) string {
+	switch period {
+	case BillingMonthly:
+		return "月"
+	case BillingYearly:
+		return "年"
+	case BillingOnce:
+		return "买断"
+	case BillingUsage:
+		return "按量"
+	}
+	return period
+}
+
+// PricingPlan 一个付费套餐
+type PricingPlan struct {
+	Name  string  `json:"name" yaml:"name"`
+	Price float64 `json:"price" yaml:"price"`
+	// Currency ISO 4217 货币代码，如 USD、CNY
+	Currency string `json:"currency,omitempty" yaml:"currency,omitempty"`
+	// Period 计费周期，取值见 BillingPeriods，为空表示未说明
+	Period string `json:"period,omitempty" yaml:"period,omitempty"`
+}
+
+// PriceLabel 返回价格的展示文本，如 20 USD/月、99 CNY（买断）、免费
+func (p PricingPlan) PriceLabel() string {
+	if p.Price == 0 {
+		return "免费"
+	}
+	label := strconv.FormatFloat(p.Price, 'f', -1, 64)
+	if p.Currency != "" {
+		label += " " + p.Currency
+	}
+	switch p.Period {
+	case "":
+	case BillingMonthly, BillingYearly:
+		label += "/" + BillingPeriodLabel(p.Period)
+	default:
+		label += "（" + BillingPeriodLabel(p.Period) + "）"
+	}
+	return label
+}
+
+func (p PricingPlan) String() string {
+	return p.Name + " " + p.PriceLabel()
+}
+
+// PriceRecord 站点以往的价格，价格变化时由 Pricing.Track 记录
+type PriceRecord struct {
+	Model string        `json:"model" yaml:"model"`
+	Plans []PricingPlan `json:"plans,omitempty" yaml:"plans,omitempty"`
+	// Since 该价格开始生效的日期（2006-01-02），不知道时为空；Until 价格变化的日期
+	Since string `json:"since,omitempty" yaml:"since,omitempty"`
+	Until string `json:"until" yaml:"until"`
+}
+
+// ModelLabel 返回定价模式的中文名称
+func (r PriceRecord) ModelLabel() string {
+	return PricingModelLabel(r.Model)
+}
+
+// Pricing 站点的定价信息
+type Pricing struct {
+	// Model 定价模式，取值见 PricingModels
+	Model string        `json:"model" yaml:"model"`
+	Plans []PricingPlan `json:"plans,omitempty" yaml:"plans,omitempty"`
+	// FreeTier 免费版的限制，如「每天 20 次对话」
+	FreeTier string `json:"free_tier,omitempty" yaml:"free_tier,omitempty"`
+	// UpdatedAt 当前价格开始生效的日期（2006-01-02）
+	UpdatedAt string `json:"updated_at,omitempty" yaml:"updated_at,omitempty"`
+	// History 以往的价格，按时间从早到晚排列
+	History []PriceRecord `json:"history,omitempty" yaml:"history,omitempty"`
+}
+
+// UnmarshalJSON 整体替换而不是合并到已有的值，解码到已有定价上时删掉的套餐不会保留下来
+func (p *Pricing) UnmarshalJSON(data []byte) error {
+	type plain Pricing
+	var v plain
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*p = Pricing(v)
+	return nil
+}
+
+// ModelLabel 返回定价模式的中文名称
+func (p *Pricing) ModelLabel() string {
+	return PricingModelLabel(p.Model)
+}
+
+// String 返回用于修订记录和差异比较的文本，不包含价格历史
+func (p Pricing) String() string {
+	parts := []string{PricingModelLabel(p.Model)}
+	if p.FreeTier != "" {
+		parts = append(parts, "免费额度："+p.FreeTier)
+	}
+	for _, plan := range p.Plans {
+		parts = append(parts, plan.String())
+	}
+	return strings.Join(parts, "；")
+}
+
+// samePrice 定价模式和套餐是否相同，免费额度等说明文字的修改不算价格变化
+func (p *Pricing) samePrice(other *Pricing) bool {
+	if p == nil || other == nil {
+		return p == other
+	}
+	return p.Model == other.Model && slices.Equal(p.Plans, other.Plans)
+}
+
+// hash 将定价的全部字段写入 Site.ContentHash，nil 与空定价不同
+func (p *Pricing) hash(write func(string)) {
+	if p == nil {
+		write("")
+		return
+	}
+	writePlans := func(plans []PricingPlan) {
+		write(strconv.Itoa(len(plans)))
+		for _, plan := range plans {
+			write(plan.Name)
+			write(strconv.FormatUint(math.Float64bits(plan.Price), 16))
+			write(plan.Currency)
+			write(plan.Period)
+		}
+	}
+	write("pricing")
+	write(p.Model)
+	writePlans(p.Plans)
+	write(p.FreeTier)
+	write(p.UpdatedAt)
+	write(strconv.Itoa(len(p.History)))
+	for _, record := range p.History {
+		write(record.Model)
+		writePlans(record.Plans)
+		write(record.Since)
+		write(record.Until)
+	}
+}
+
+// Track 以 before 为修改前的定价返回保存用的定价：沿用 before 的价格历史，
+// 定价模式或套餐变化时把 before 的价格记入历史，并以 date 作为新价格的生效日期
+// p 为 nil（清除定价）时价格历史一并清除；返回新的值，不修改 p 和 before
+func (p *Pricing) Track(before *Pricing, date string) *Pricing {
+	if p == nil {
+		return nil
+	}
+	tracked := *p
+	if before == nil {
+		if tracked.UpdatedAt == "" {
+			tracked.UpdatedAt = date
+		}
+		return &tracked
+	}
+
+	tracked.History = before.History
+	tracked.UpdatedAt = before.UpdatedAt
+	if p.samePrice(before) {
+		return &tracked
+	}
+	// 同一天内的多次修改只记录一次变化
+	if before.UpdatedAt != date {
+		tracked.History = append(slices.Clone(before.History), PriceRecord{
+			Model: before.Model,
+			Plans: before.Plans,
+			Since: before.UpdatedAt,
+			Until: date,
+		})
+	}
+	tracked.UpdatedAt = date
+	return &tracked
+}
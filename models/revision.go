@@ -0,0 +1,119 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// 站点修订记录的操作类型
+const (
+	RevisionCreate  = "create"
+	RevisionUpdate  = "update"
+	RevisionDelete  = "delete"
+	RevisionRestore = "restore"
+	RevisionPurge   = "purge" // 从回收站彻底删除，After 只保留 ID 和删除标记
+)
+
+// Revision 站点的一次修改记录，保存修改前后的完整快照
+type Revision struct {
+	ID     int64     `json:"id"`
+	SiteID string    `json:"site_id"`
+	Action string    `json:"action"`
+	Actor  string    `json:"actor"`
+	Time   time.Time `json:"time"`
+	Before *Site     `json:"before,omitempty"`
+	After  *Site     `json:"after,omitempty"`
+}
+
+// Snapshot 返回该修订完成后的站点状态
+func (r Revision) Snapshot() *Site {
+	if r.After != nil {
+		return r.After
+	}
+	return r.Before
+}
+
+// FieldChange 两个站点快照之间某个字段的差异
+type FieldChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+// DiffSites 逐字段比较两个站点快照，nil 视为空站点
+func DiffSites(before, after *Site) []FieldChange {
+	var a, b Site
+	if before != nil {
+		a = *before
+	}
+	if after != nil {
+		b = *after
+	}
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	t := va.Type()
+
+	var changes []FieldChange
+	for i := 0; i < t.NumField(); i++ {
+		name := jsonName(t.Field(i))
+		if name == "" {
+			continue
+		}
+		x, y := formatField(va.Field(i)), formatField(vb.Field(i))
+		if x != y {
+			changes = append(changes, FieldChange{Field: name, Before: x, After: y})
+		}
+	}
+	return changes
+}
+
+// SiteFieldIndex 返回 JSON 字段名对应的 Site 字段下标，不写入数据文件的字段返回 false
+func SiteFieldIndex(name string) (int, bool) {
+	t := reflect.TypeOf(Site{})
+	for i := 0; i < t.NumField(); i++ {
+		if name != "" && jsonName(t.Field(i)) == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// FieldValue 返回站点某个字段用于展示的文本，格式与 DiffSites 相同
+func FieldValue(site Site, name string) string {
+	i, ok := SiteFieldIndex(name)
+	if !ok {
+		return ""
+	}
+	return formatField(reflect.ValueOf(site).Field(i))
+}
+
+func jsonName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func formatField(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Slice:
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return strings.Join(parts, ", ")
+	case reflect.Bool:
+		if v.Bool() {
+			return "是"
+		}
+		return ""
+	default:
+		if v.IsZero() {
+			return ""
+		}
+		return fmt.Sprint(v.Interface())
+	}
+}
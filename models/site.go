@@ -1,22 +1,81 @@
 // models/site.go
 package models
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash/fnv"
+	"math"
+	"strconv"
+)
+
 type SiteData struct {
 	Sites []Site `json:"sites"`
 }
 
 type Site struct {
-	Name        string   `json:"name"`
-	URL         string   `json:"url"`
-	Description string   `json:"description"`
-	Logo        string   `json:"logo"`
-	Tags        []string `json:"tags"`
-	Category    string   `json:"category,omitempty"`
-	Rating      float64  `json:"rating,omitempty"`
-	Visits      int      `json:"visits,omitempty"`
-	Featured    bool     `json:"featured,omitempty"`
-	CreatedAt   string   `json:"created_at,omitempty"`
-	Deleted     bool     `json:"deleted,omitempty"`
+	ID          string   `json:"id" yaml:"id"`
+	Name        string   `json:"name" yaml:"name"`
+	URL         string   `json:"url" yaml:"url"`
+	Description string   `json:"description" yaml:"description"`
+	Logo        string   `json:"logo" yaml:"logo"`
+	Tags        []string `json:"tags" yaml:"tags"`
+	Category    string   `json:"category,omitempty" yaml:"category,omitempty"`
+	Rating      float64  `json:"rating,omitempty" yaml:"rating,omitempty"`
+	Visits      int      `json:"visits,omitempty" yaml:"visits,omitempty"`
+	Featured    bool     `json:"featured,omitempty" yaml:"featured,omitempty"`
+	CreatedAt   string   `json:"created_at,omitempty" yaml:"created_at,omitempty"`
+	Deleted     bool     `json:"deleted,omitempty" yaml:"deleted,omitempty"`
+	// DeletedAt 删除时间（RFC 3339），DeletedBy 删除站点的管理员，站点在回收站中时有值
+	DeletedAt string `json:"deleted_at,omitempty" yaml:"deleted_at,omitempty"`
+	DeletedBy string `json:"deleted_by,omitempty" yaml:"deleted_by,omitempty"`
+	// Pricing 定价信息，nil 表示未知；修改时整体替换，不要修改指向的值，复制的站点共用同一份定价
+	Pricing *Pricing `json:"pricing,omitempty" yaml:"pricing,omitempty"`
+
+	// Pack 站点来源的数据文件，Overridden 表示在 custom.json 中被修改过，均不写入数据文件
+	Pack       string `json:"-" yaml:"-"`
+	Overridden bool   `json:"-" yaml:"-"`
+}
+
+// Version 返回站点内容的版本标识（序列化内容的哈希），用于发现编辑期间站点是否已被他人修改
+// 不写入数据文件的字段不影响版本，空标签与 nil 视为相同
+func (s Site) Version() string {
+	if len(s.Tags) == 0 {
+		s.Tags = nil
+	}
+	data, _ := json.Marshal(s)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ContentHash 返回站点内容的快速哈希，用于重新加载时找出内容没有变化、可以沿用展示数据的站点
+// 与 Version 覆盖相同的字段，但不经过 JSON 序列化，十万个站点也只需几十毫秒；新增字段时需要同时加入
+func (s Site) ContentHash() uint64 {
+	h := fnv.New64a()
+	write := func(v string) {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+	write(s.ID)
+	write(s.Name)
+	write(s.URL)
+	write(s.Description)
+	write(s.Logo)
+	write(strconv.Itoa(len(s.Tags)))
+	for _, tag := range s.Tags {
+		write(tag)
+	}
+	write(s.Category)
+	write(strconv.FormatUint(math.Float64bits(s.Rating), 16))
+	write(strconv.Itoa(s.Visits))
+	write(strconv.FormatBool(s.Featured))
+	write(s.CreatedAt)
+	write(strconv.FormatBool(s.Deleted))
+	write(s.DeletedAt)
+	write(s.DeletedBy)
+	s.Pricing.hash(write)
+	return h.Sum64()
 }
 
 // SiteDisplay 用于前端显示的站点信息，包含额外的显示字段
@@ -24,4 +83,6 @@ type SiteDisplay struct {
 	Site
 	Color    string `json:"color"`
 	Initials string `json:"initials"`
+	// TagBadges 卡片中显示的标签，带有注册表中的颜色和说明
+	TagBadges []TagBadge `json:"tagBadges,omitempty"`
 }
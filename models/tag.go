@@ -0,0 +1,19 @@
+package models
+
+// Tag 标签注册表中的标签，站点保存时别名会被替换为规范名称
+type Tag struct {
+	// Name 规范名称，站点中显示和保存的标签
+	Name string `json:"name" yaml:"name"`
+	// Aliases 同一标签的其它写法，如「对话AI」之于「AI对话」
+	Aliases []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	// Color 标签颜色，#rrggbb 格式，为空时使用默认样式
+	Color       string `json:"color,omitempty" yaml:"color,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// TagBadge 首页站点卡片中显示的标签
+type TagBadge struct {
+	Name        string
+	Color       string
+	Description string
+}
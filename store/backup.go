@@ -0,0 +1,154 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	backupPrefix     = "custom-"
+	backupSuffix     = ".json"
+	backupTimeLayout = "20060102-150405.000000"
+
+	// defaultBackupKeep 未配置时保留的备份数量
+	defaultBackupKeep = 10
+)
+
+// ErrInvalidBackup 备份名称非法或内容无法解析
+var ErrInvalidBackup = errors.New("无效的备份文件")
+
+// Backup 一份 custom.json 的历史备份
+type Backup struct {
+	Name string
+	Time time.Time
+	Size int64
+}
+
+// Backupper 支持备份与恢复的存储实现
+type Backupper interface {
+	// ListBackups 按时间倒序返回所有备份
+	ListBackups() ([]Backup, error)
+	// RestoreBackup 用指定备份覆盖当前数据
+	RestoreBackup(name string) error
+}
+
+func (s *JSONStore) ListBackups() ([]Backup, error) {
+	entries, err := os.ReadDir(s.backupDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取备份目录失败: %w", err)
+	}
+
+	var backups []Backup
+	for _, entry := range entries {
+		t, ok := parseBackupName(entry.Name())
+		if !ok || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, Backup{Name: entry.Name(), Time: t, Size: info.Size()})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Time.After(backups[j].Time)
+	})
+	return backups, nil
+}
+
+func (s *JSONStore) RestoreBackup(name string) error {
+	if _, ok := parseBackupName(name); !ok || filepath.Base(name) != name {
+		return ErrInvalidBackup
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(filepath.Join(s.backupDir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrInvalidBackup
+	}
+	if err != nil {
+		return fmt.Errorf("读取备份文件失败: %w", err)
+	}
+	if !json.Valid(data) {
+		return ErrInvalidBackup
+	}
+
+	// 写入协程同样持有 s.mu，恢复不会与排队的修改交错
+	s.cache = nil
+	return s.writeCustom(data)
+}
+
+// writeCustom 备份当前 custom.json 后原子写入新内容，调用方需持有 s.mu
+func (s *JSONStore) writeCustom(data []byte) error {
+	if err := s.backupCustom(); err != nil {
+		// 备份失败不应阻止保存，仅记录日志
+		log.Printf("备份 custom.json 失败: %v", err)
+	}
+	if err := s.writeFile(s.customPath, data); err != nil {
+		return fmt.Errorf("写入 custom.json 文件失败: %w", err)
+	}
+	return nil
+}
+
+// backupCustom 将当前 custom.json 复制到备份目录，并清理超出保留数量的旧备份
+func (s *JSONStore) backupCustom() error {
+	data, err := os.ReadFile(s.customPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.backupDir, 0755); err != nil {
+		return err
+	}
+	name := backupPrefix + strings.Replace(time.Now().Format(backupTimeLayout), ".", "", 1) + backupSuffix
+	if err := writeFileAtomic(filepath.Join(s.backupDir, name), data, 0644); err != nil {
+		return err
+	}
+
+	return s.pruneBackups()
+}
+
+func (s *JSONStore) pruneBackups() error {
+	backups, err := s.ListBackups()
+	if err != nil {
+		return err
+	}
+	for i := s.backupKeep; i < len(backups); i++ {
+		if err := os.Remove(filepath.Join(s.backupDir, backups[i].Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseBackupName 从备份文件名中解析备份时间
+func parseBackupName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupSuffix) {
+		return time.Time{}, false
+	}
+	stamp := strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), backupSuffix)
+	if len(stamp) != len(backupTimeLayout)-1 {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(backupTimeLayout, stamp[:15]+"."+stamp[15:], time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
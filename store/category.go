@@ -0,0 +1,228 @@
+package store
+
+import (
+	"ai-navigator/models"
+	"ai-navigator/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// categoriesFile 分类数据文件，不作为站点包加载
+const categoriesFile = "categories.json"
+
+var (
+	// ErrCategoryNotFound 分类不存在
+	ErrCategoryNotFound = errors.New("分类不存在")
+	// ErrCategoryExists 标识相同的分类已存在
+	ErrCategoryExists = errors.New("分类已存在")
+	// ErrCategoryHasChildren 分类下还有子分类，不能删除
+	ErrCategoryHasChildren = errors.New("分类下还有子分类")
+)
+
+// CategoryStore 支持分类管理的存储实现，分类的标识创建后不能修改
+type CategoryStore interface {
+	// ListCategories 按上级分类、排序和名称返回全部分类
+	ListCategories() ([]models.Category, error)
+	// CreateCategory 新增分类，标识已存在时返回 ErrCategoryExists
+	CreateCategory(category models.Category) error
+	// UpdateCategory 更新标识为 category.Slug 的分类
+	UpdateCategory(category models.Category) error
+	// DeleteCategory 删除分类，还有子分类时返回 ErrCategoryHasChildren；使用该分类的站点保持不变
+	DeleteCategory(slug string) error
+}
+
+// validateCategories 校验全部分类：标识和名称不能为空且不能重复，上级分类必须存在且不能形成循环
+func validateCategories(file string, categories []models.Category) ValidationErrors {
+	var errs ValidationErrors
+	add := func(i int, category models.Category, field, reason string) {
+		errs = append(errs, ValidationError{File: file, Index: i, SiteID: category.Slug, Field: field, Reason: reason})
+	}
+
+	slugs := make(map[string]int, len(categories))
+	names := make(map[string]int, len(categories))
+	for i, category := range categories {
+		switch {
+		case category.Slug == "":
+			add(i, category, "slug", "分类标识不能为空")
+		case utils.Slugify(category.Slug) != category.Slug:
+			add(i, category, "slug", "分类标识只能包含小写字母、数字和连字符")
+		default:
+			if first, ok := slugs[category.Slug]; ok {
+				add(i, category, "slug", fmt.Sprintf("标识与第 %d 个分类重复", first))
+			} else {
+				slugs[category.Slug] = i
+			}
+		}
+
+		name := strings.TrimSpace(category.Name)
+		if name == "" {
+			add(i, category, "name", "分类名称不能为空")
+		} else if first, ok := names[name]; ok {
+			add(i, category, "name", fmt.Sprintf("名称与第 %d 个分类重复", first))
+		} else {
+			names[name] = i
+		}
+
+		if category.Icon != "" && strings.Contains(category.Icon, "/") &&
+			!strings.HasPrefix(category.Icon, "/") && !isHTTPURL(category.Icon) {
+			add(i, category, "icon", "图标必须是 emoji、以 / 开头的站内路径或完整网址")
+		}
+	}
+
+	for i, category := range categories {
+		if category.Parent == "" {
+			continue
+		}
+		if _, ok := slugs[category.Parent]; !ok {
+			add(i, category, "parent", fmt.Sprintf("上级分类 %s 不存在", category.Parent))
+			continue
+		}
+		// 沿上级分类向上查找，回到自身说明形成了循环
+		seen := map[string]bool{category.Slug: true}
+		for parent := category.Parent; parent != ""; {
+			if seen[parent] {
+				add(i, category, "parent", "上级分类不能是自身或自身的子分类")
+				break
+			}
+			seen[parent] = true
+			j, ok := slugs[parent]
+			if !ok {
+				break
+			}
+			parent = categories[j].Parent
+		}
+	}
+	return errs
+}
+
+// sortCategories 按上级分类、排序和名称排列，同一上级的分类相邻
+func sortCategories(categories []models.Category) {
+	sort.SliceStable(categories, func(i, j int) bool {
+		a, b := categories[i], categories[j]
+		if a.Parent != b.Parent {
+			return a.Parent < b.Parent
+		}
+		if a.Order != b.Order {
+			return a.Order < b.Order
+		}
+		return a.Name < b.Name
+	})
+}
+
+// indexOfCategory 返回标识为 slug 的分类的下标，不存在时返回 -1
+func indexOfCategory(categories []models.Category, slug string) int {
+	for i, category := range categories {
+		if category.Slug == slug {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasChildCategory 判断是否有以 slug 为上级的分类
+func hasChildCategory(categories []models.Category, slug string) bool {
+	for _, category := range categories {
+		if category.Parent == slug {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *JSONStore) ListCategories() ([]models.Category, error) {
+	s.catMu.Lock()
+	defer s.catMu.Unlock()
+
+	categories, err := s.readCategories()
+	if err != nil {
+		return nil, err
+	}
+	sortCategories(categories)
+	return categories, nil
+}
+
+func (s *JSONStore) CreateCategory(category models.Category) error {
+	return s.mutateCategories(func(categories []models.Category) ([]models.Category, error) {
+		if indexOfCategory(categories, category.Slug) >= 0 {
+			return nil, ErrCategoryExists
+		}
+		return append(categories, category), nil
+	})
+}
+
+func (s *JSONStore) UpdateCategory(category models.Category) error {
+	return s.mutateCategories(func(categories []models.Category) ([]models.Category, error) {
+		i := indexOfCategory(categories, category.Slug)
+		if i < 0 {
+			return nil, ErrCategoryNotFound
+		}
+		categories[i] = category
+		return categories, nil
+	})
+}
+
+func (s *JSONStore) DeleteCategory(slug string) error {
+	return s.mutateCategories(func(categories []models.Category) ([]models.Category, error) {
+		i := indexOfCategory(categories, slug)
+		if i < 0 {
+			return nil, ErrCategoryNotFound
+		}
+		if hasChildCategory(categories, slug) {
+			return nil, ErrCategoryHasChildren
+		}
+		return append(categories[:i], categories[i+1:]...), nil
+	})
+}
+
+// mutateCategories 读取全部分类，修改并校验后写回 categories.json
+// 分类数量很少且修改不频繁，不经过 custom.json 的写入协程
+func (s *JSONStore) mutateCategories(fn func(categories []models.Category) ([]models.Category, error)) error {
+	s.catMu.Lock()
+	defer s.catMu.Unlock()
+
+	categories, err := s.readCategories()
+	if err != nil {
+		return err
+	}
+	categories, err = fn(categories)
+	if err != nil {
+		return err
+	}
+	if errs := validateCategories(categoriesFile, categories); len(errs) > 0 {
+		return errs
+	}
+	sortCategories(categories)
+
+	data, err := json.MarshalIndent(categories, "", "    ")
+	if err != nil {
+		return fmt.Errorf("序列化JSON失败: %w", err)
+	}
+	if err := s.writeFile(s.categoriesPath, data); err != nil {
+		return fmt.Errorf("写入 %s 文件失败: %w", categoriesFile, err)
+	}
+	return nil
+}
+
+// readCategories 读取并校验 categories.json，文件不存在时没有分类，调用方需持有 s.catMu
+func (s *JSONStore) readCategories() ([]models.Category, error) {
+	data, err := os.ReadFile(s.categoriesPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 文件失败: %w", categoriesFile, err)
+	}
+
+	var categories []models.Category
+	if err := json.Unmarshal(data, &categories); err != nil {
+		return nil, ValidationErrors{{File: categoriesFile, Index: -1, Reason: fmt.Sprintf("JSON 格式错误: %v", err)}}
+	}
+	if errs := validateCategories(categoriesFile, categories); len(errs) > 0 {
+		return nil, errs
+	}
+	return categories, nil
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"ai-navigator/models"
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// Drift 支持检测站点包更新与后台修改冲突的存储实现
+// 覆盖记录保存了被修改字段在修改时站点包中的值（基准），站点包之后又修改了同一字段即为冲突
+type Drift interface {
+	// Conflicts 返回全部存在冲突的站点
+	Conflicts() ([]Conflict, error)
+	// ResolveConflict 用合并后的站点替换后台的修改，并以站点包当前的数据作为新的基准，返回保存后的站点
+	ResolveConflict(id string, merged models.Site) (models.Site, error)
+}
+
+// Conflict 站点包与后台对同一站点的修改冲突，三方快照用于逐字段合并
+type Conflict struct {
+	ID string
+	// Base 后台修改时站点包中的站点，Upstream 站点包当前的站点，Ours 后台修改后的站点
+	Base, Upstream, Ours models.Site
+	// Fields 冲突的字段：站点包修改了该字段，且与后台修改的值不同，按 models.Site 的字段顺序排列
+	Fields []string
+}
+
+func (s *JSONStore) Conflicts() ([]Conflict, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upstream, customSites, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]int, len(upstream))
+	for i, site := range upstream {
+		byID[site.ID] = i
+	}
+
+	var conflicts []Conflict
+	for _, site := range customSites {
+		if site.Deleted {
+			continue
+		}
+		i, ok := byID[site.ID]
+		if !ok {
+			continue
+		}
+		if conflict, ok := findConflict(site, upstream[i], s.overlay.bases[site.ID]); ok {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	return conflicts, nil
+}
+
+func (s *JSONStore) ResolveConflict(id string, merged models.Site) (models.Site, error) {
+	var resolved models.Site
+	err := s.mutateCustom(func(upstream, customSites []models.Site) ([]models.Site, error) {
+		i := indexOf(customSites, id)
+		if i < 0 || customSites[i].Deleted || indexOf(upstream, id) < 0 {
+			return nil, ErrNotFound
+		}
+		current := customSites[i]
+		resolved = merged
+		resolved.ID = id
+		resolved.Visits, resolved.CreatedAt = current.Visits, current.CreatedAt
+		resolved.Pack, resolved.Overridden = "", false
+		customSites[i] = resolved
+		// 去掉旧的基准，写回时以站点包当前的值为准
+		delete(s.overlay.bases, id)
+		return customSites, nil
+	})
+	return resolved, err
+}
+
+// findConflict 比较三方快照，base 为空（旧数据没有记录基准）时视为没有冲突
+func findConflict(ours, up models.Site, base map[string]json.RawMessage) (Conflict, bool) {
+	if len(base) == 0 {
+		return Conflict{}, false
+	}
+	baseSite, err := applyBase(up, base)
+	if err != nil {
+		return Conflict{}, false
+	}
+
+	conflict := Conflict{ID: ours.ID, Base: baseSite, Upstream: up, Ours: ours}
+	conflict.Upstream.Pack = ""
+	vb, vu, vo := reflect.ValueOf(baseSite), reflect.ValueOf(up), reflect.ValueOf(ours)
+	t := vb.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := jsonFieldName(t.Field(i))
+		if _, ok := base[name]; !ok {
+			continue
+		}
+		b, errB := fieldJSON(vb.Field(i))
+		u, errU := fieldJSON(vu.Field(i))
+		o, errO := fieldJSON(vo.Field(i))
+		if errB != nil || errU != nil || errO != nil {
+			continue
+		}
+		if !bytes.Equal(b, u) && !bytes.Equal(o, u) {
+			conflict.Fields = append(conflict.Fields, name)
+		}
+	}
+	return conflict, len(conflict.Fields) > 0
+}
+
+// applyBase 将记录的基准值应用到站点包当前的站点上，得到后台修改时站点包中的站点
+func applyBase(up models.Site, base map[string]json.RawMessage) (models.Site, error) {
+	data, err := json.Marshal(base)
+	if err != nil {
+		return models.Site{}, err
+	}
+	site := up
+	site.Pack = ""
+	err = applyFields(&site, data)
+	return site, err
+}
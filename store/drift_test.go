@@ -0,0 +1,94 @@
+package store
+
+import (
+	"ai-navigator/config"
+	"ai-navigator/models"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// newDriftTestStore 创建只有一个站点包 ai.json 的存储，并在后台修改站点的名称和定价
+func newDriftTestStore(t *testing.T) (*JSONStore, string) {
+	t.Helper()
+	dir := t.TempDir()
+	writeDriftPack(t, dir, overlayUpstream())
+	s := NewJSONStore(config.StorageConfig{DataDir: dir})
+	t.Cleanup(func() { s.Close() })
+
+	edited := overlayUpstream()
+	edited.Name = "Chat Plus"
+	edited.Pricing = &models.Pricing{Model: models.PricingPaid}
+	if err := s.Update("chat", edited); err != nil {
+		t.Fatal(err)
+	}
+	return s, dir
+}
+
+// writeDriftPack 把 site 写入站点包 ai.json
+func writeDriftPack(t *testing.T, dir string, site models.Site) {
+	t.Helper()
+	data, err := json.Marshal([]models.Site{site})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ai.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConflictsOverriddenFieldChanged(t *testing.T) {
+	s, dir := newDriftTestStore(t)
+
+	up := overlayUpstream()
+	up.Name = "Chat Pro"
+	writeDriftPack(t, dir, up)
+	s.invalidate()
+
+	conflicts, err := s.Conflicts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 1 || conflicts[0].ID != "chat" || !slices.Equal(conflicts[0].Fields, []string{"name"}) {
+		t.Fatalf("冲突 = %+v，应为 chat 的 name 字段", conflicts)
+	}
+	c := conflicts[0]
+	if c.Base.Name != "Chat" || c.Upstream.Name != "Chat Pro" || c.Ours.Name != "Chat Plus" {
+		t.Fatalf("三方名称 = %q / %q / %q", c.Base.Name, c.Upstream.Name, c.Ours.Name)
+	}
+
+	// 计算基准不能改到站点包的数据
+	pack, err := s.Upstream("chat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pack.Pricing == nil || pack.Pricing.Model != models.PricingFreemium || len(pack.Pricing.Plans) != 1 {
+		t.Fatalf("站点包的定价被改动: %+v", pack.Pricing)
+	}
+}
+
+func TestConflictsOtherFieldChanged(t *testing.T) {
+	s, dir := newDriftTestStore(t)
+
+	up := overlayUpstream()
+	up.Description = "站点包更新了描述"
+	writeDriftPack(t, dir, up)
+	s.invalidate()
+
+	conflicts, err := s.Conflicts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 0 {
+		t.Fatalf("冲突 = %+v，站点包只修改了没有覆盖的字段，应没有冲突", conflicts)
+	}
+	got, err := s.Get("chat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "Chat Plus" || got.Description != "站点包更新了描述" {
+		t.Fatalf("站点 = %q / %q，应保留后台的名称并采用站点包的新描述", got.Name, got.Description)
+	}
+}
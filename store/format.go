@@ -0,0 +1,324 @@
+package store
+
+import (
+	"ai-navigator/models"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 站点数据文件支持的格式
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatCSV  = "csv"
+)
+
+// Formats 全部支持的格式，按推荐程度排序
+var Formats = []string{FormatJSON, FormatYAML, FormatCSV}
+
+// csvColumns CSV 文件的列，标签列中的多个标签用 csvTagSeparator 分隔
+// pricing 列只有定价模式，套餐和价格历史需要使用 JSON 或 YAML 格式
+var csvColumns = []string{"id", "name", "url", "description", "logo", "tags", "category", "rating", "visits", "featured", "created_at", "deleted", "pricing"}
+
+const csvTagSeparator = "|"
+
+// utf8BOM Excel 打开不带 BOM 的 UTF-8 CSV 时中文会乱码
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// FormatOf 根据扩展名判断数据文件格式，不支持的文件返回空字符串
+func FormatOf(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".csv":
+		return FormatCSV
+	}
+	return ""
+}
+
+// decodeSites 按扩展名解析站点数据文件，格式错误会转换为 ValidationErrors
+func decodeSites(file string, data []byte) ([]models.Site, error) {
+	switch FormatOf(file) {
+	case FormatJSON:
+		return decodeSitesJSON(file, data)
+	case FormatYAML:
+		return decodeSitesYAML(file, data)
+	case FormatCSV:
+		return decodeSitesCSV(file, data)
+	}
+	return nil, fmt.Errorf("不支持的数据文件格式: %s", file)
+}
+
+// EncodeSites 将站点列表编码为指定格式
+func EncodeSites(format string, sites []models.Site) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(sites, "", "    ")
+		if err != nil {
+			return nil, fmt.Errorf("序列化JSON失败: %w", err)
+		}
+		return data, nil
+	case FormatYAML:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(sites); err != nil {
+			return nil, fmt.Errorf("序列化YAML失败: %w", err)
+		}
+		if err := enc.Close(); err != nil {
+			return nil, fmt.Errorf("序列化YAML失败: %w", err)
+		}
+		return buf.Bytes(), nil
+	case FormatCSV:
+		return encodeSitesCSV(sites)
+	}
+	return nil, fmt.Errorf("不支持的导出格式: %s", format)
+}
+
+func decodeSitesYAML(file string, data []byte) ([]models.Site, error) {
+	var sites []models.Site
+	err := yaml.Unmarshal(data, &sites)
+	if err == nil {
+		return sites, nil
+	}
+
+	// yaml.v3 的错误信息已包含行号，例如 "line 3: cannot unmarshal !!str `abc` into float64"
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		var errs ValidationErrors
+		for _, msg := range typeErr.Errors {
+			errs = append(errs, ValidationError{File: file, Index: -1, Reason: "YAML 字段类型错误: " + msg})
+		}
+		return nil, errs
+	}
+	return nil, ValidationErrors{{File: file, Index: -1, Reason: "YAML 语法错误: " + strings.TrimPrefix(err.Error(), "yaml: ")}}
+}
+
+// CSVRow CSV 文件中的一行站点数据
+type CSVRow struct {
+	// Line 该行在文件中的行号，从 1 开始，表头为第 1 行
+	Line int
+	Site models.Site
+	// Errors 该行中无法解析的字段，为空表示解析成功
+	Errors ValidationErrors
+}
+
+// ParseSitesCSV 逐行解析带表头的 CSV，列按表头名称匹配，未知列会被忽略，
+// 返回每一行的结果以及表头中出现的列；表头缺少必需的列或 CSV 格式错误时返回 ValidationErrors
+func ParseSitesCSV(file string, data []byte) ([]CSVRow, map[string]bool, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, utf8BOM)))
+	r.TrimLeadingSpace = true
+	// 电子表格导出的文件末尾常有缺少空列的行，缺少的列按空值处理
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, csvSyntaxError(file, err)
+	}
+
+	columns := make(map[string]int)
+	present := make(map[string]bool)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		columns[name] = i
+		present[name] = true
+	}
+	for _, required := range []string{"name", "url"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, ValidationErrors{{File: file, Index: -1, Field: required, Reason: fmt.Sprintf("表头缺少 %s 列", required)}}
+		}
+	}
+
+	var rows []CSVRow
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, csvSyntaxError(file, err)
+		}
+		line, _ := r.FieldPos(0)
+		row := CSVRow{Line: line}
+		index := len(rows)
+
+		get := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		invalid := func(column, kind string) {
+			row.Errors = append(row.Errors, ValidationError{File: file, Index: index, SiteID: get("id"), Field: column,
+				Reason: fmt.Sprintf("第 %d 行 %s 列不是有效的%s", line, column, kind)})
+		}
+
+		site := models.Site{
+			ID:          get("id"),
+			Name:        get("name"),
+			URL:         get("url"),
+			Description: get("description"),
+			Logo:        get("logo"),
+			Tags:        []string{},
+			Category:    get("category"),
+			CreatedAt:   get("created_at"),
+		}
+		for _, tag := range strings.Split(get("tags"), csvTagSeparator) {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				site.Tags = append(site.Tags, tag)
+			}
+		}
+		if v := get("rating"); v != "" {
+			if site.Rating, err = strconv.ParseFloat(v, 64); err != nil {
+				invalid("rating", "数字")
+			}
+		}
+		if v := get("visits"); v != "" {
+			if site.Visits, err = strconv.Atoi(v); err != nil {
+				invalid("visits", "整数")
+			}
+		}
+		if v := get("featured"); v != "" {
+			if site.Featured, err = strconv.ParseBool(v); err != nil {
+				invalid("featured", "布尔值（true/false）")
+			}
+		}
+		if v := get("deleted"); v != "" {
+			if site.Deleted, err = strconv.ParseBool(v); err != nil {
+				invalid("deleted", "布尔值（true/false）")
+			}
+		}
+		if v := get("pricing"); v != "" {
+			site.Pricing = &models.Pricing{Model: strings.ToLower(v)}
+		}
+		row.Site = site
+		rows = append(rows, row)
+	}
+	return rows, present, nil
+}
+
+// ApplyCSVColumns 用 CSV 行中出现的列覆盖站点的对应字段，CSV 中没有的列保留原值
+func ApplyCSVColumns(dst *models.Site, src models.Site, columns map[string]bool) {
+	fields := map[string]func(){
+		"name":        func() { dst.Name = src.Name },
+		"url":         func() { dst.URL = src.URL },
+		"description": func() { dst.Description = src.Description },
+		"logo":        func() { dst.Logo = src.Logo },
+		"tags":        func() { dst.Tags = src.Tags },
+		"category":    func() { dst.Category = src.Category },
+		"rating":      func() { dst.Rating = src.Rating },
+		"visits":      func() { dst.Visits = src.Visits },
+		"featured":    func() { dst.Featured = src.Featured },
+		"created_at":  func() { dst.CreatedAt = src.CreatedAt },
+		"pricing":     func() { dst.Pricing = withPricingModel(dst.Pricing, src.Pricing) },
+	}
+	for column, apply := range fields {
+		if columns[column] {
+			apply()
+		}
+	}
+}
+
+// withPricingModel 按 CSV 的 pricing 列修改定价模式，保留原有的套餐和价格历史；该列为空时清除定价
+func withPricingModel(dst, src *models.Pricing) *models.Pricing {
+	if src == nil {
+		return nil
+	}
+	if dst == nil {
+		return src
+	}
+	pricing := *dst
+	pricing.Model = src.Model
+	return &pricing
+}
+
+// decodeSitesCSV 解析 CSV 站点包，任一行解析失败时返回全部错误
+func decodeSitesCSV(file string, data []byte) ([]models.Site, error) {
+	rows, _, err := ParseSitesCSV(file, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var sites []models.Site
+	var errs ValidationErrors
+	for _, row := range rows {
+		errs = append(errs, row.Errors...)
+		sites = append(sites, row.Site)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return sites, nil
+}
+
+func csvSyntaxError(file string, err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return ValidationErrors{{File: file, Index: -1, Reason: fmt.Sprintf("第 %d 行 CSV 格式错误: %v", parseErr.Line, parseErr.Err)}}
+	}
+	return ValidationErrors{{File: file, Index: -1, Reason: err.Error()}}
+}
+
+func encodeSitesCSV(sites []models.Site) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(utf8BOM)
+
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvColumns); err != nil {
+		return nil, fmt.Errorf("序列化CSV失败: %w", err)
+	}
+	for _, site := range sites {
+		record := []string{
+			site.ID,
+			site.Name,
+			site.URL,
+			site.Description,
+			site.Logo,
+			strings.Join(site.Tags, csvTagSeparator),
+			site.Category,
+			"",
+			"",
+			"",
+			site.CreatedAt,
+			"",
+			"",
+		}
+		if site.Rating != 0 {
+			record[7] = strconv.FormatFloat(site.Rating, 'f', -1, 64)
+		}
+		if site.Visits != 0 {
+			record[8] = strconv.Itoa(site.Visits)
+		}
+		if site.Featured {
+			record[9] = "true"
+		}
+		if site.Deleted {
+			record[11] = "true"
+		}
+		if site.Pricing != nil {
+			record[12] = site.Pricing.Model
+		}
+		if err := w.Write(record); err != nil {
+			return nil, fmt.Errorf("序列化CSV失败: %w", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("序列化CSV失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
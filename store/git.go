@@ -0,0 +1,158 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrGitDisabled 未启用 git 提交或数据目录不在 git 版本库中
+var ErrGitDisabled = errors.New("未启用 git 提交")
+
+// Commit 数据版本库中的一次提交
+type Commit struct {
+	Hash    string
+	Author  string
+	Email   string
+	Time    time.Time
+	Subject string
+}
+
+// ShortHash 提交哈希的前 8 位
+func (c Commit) ShortHash() string {
+	if len(c.Hash) > 8 {
+		return c.Hash[:8]
+	}
+	return c.Hash
+}
+
+// Committer 将后台修改提交到 git 版本库的存储实现
+type Committer interface {
+	// Commit 提交后台写入的数据文件，author 为操作的管理员，未启用时直接返回 nil
+	Commit(message, author string) error
+	// CommitLog 返回数据目录最近的 limit 条提交，未启用时返回 ErrGitDisabled
+	CommitLog(limit int) ([]Commit, error)
+}
+
+func (s *JSONStore) Commit(message, author string) error {
+	if s.git == nil {
+		return nil
+	}
+	return s.git.commit([]string{s.customPath, s.revisionPath, s.categoriesPath, s.tagsPath}, message, author)
+}
+
+func (s *JSONStore) CommitLog(limit int) ([]Commit, error) {
+	if s.git == nil {
+		return nil, ErrGitDisabled
+	}
+	return s.git.log(limit)
+}
+
+// gitRepo 数据目录所在的 git 工作区，通过 git 命令行操作
+type gitRepo struct {
+	dir string
+	// identity 本机未配置 git 用户时使用的提交者信息
+	identity []string
+
+	mu sync.Mutex
+}
+
+// openGitRepo 检查 dir 是否位于 git 工作区中
+func openGitRepo(dir string) (*gitRepo, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &gitRepo{dir: abs}
+	if _, err := g.run("rev-parse", "--is-inside-work-tree"); err != nil {
+		return nil, fmt.Errorf("数据目录 %s 不在 git 版本库中: %w", dir, err)
+	}
+	if _, err := g.run("config", "user.email"); err != nil {
+		g.identity = []string{"-c", "user.name=ai-navigator", "-c", "user.email=ai-navigator@localhost"}
+	}
+	return g, nil
+}
+
+func (g *gitRepo) run(args ...string) (string, error) {
+	cmd := exec.Command("git", append(append([]string{"-C", g.dir}, g.identity...), args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
+
+// commit 只提交指定的文件，工作区中其它未提交的修改不受影响；文件没有变化时不产生提交
+func (g *gitRepo) commit(paths []string, message, author string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var files []string
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, abs)
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	if _, err := g.run(append([]string{"add", "--"}, files...)...); err != nil {
+		return err
+	}
+	// 暂存区与 HEAD 一致时 diff 返回 0，表示没有需要提交的修改
+	if _, err := g.run(append([]string{"diff", "--cached", "--quiet", "--"}, files...)...); err == nil {
+		return nil
+	}
+
+	args := []string{"commit", "-m", message, "--author", fmt.Sprintf("%s <%s@ai-navigator>", author, author), "--"}
+	_, err := g.run(append(args, files...)...)
+	return err
+}
+
+// log 返回影响数据目录的最近提交
+func (g *gitRepo) log(limit int) ([]Commit, error) {
+	out, err := g.run("log", "-n", strconv.Itoa(limit), "--format=%H%x1f%an%x1f%ae%x1f%at%x1f%s", "--", ".")
+	if err != nil {
+		// 还没有任何提交的版本库
+		if strings.Contains(err.Error(), "does not have any commits") {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 5 {
+			continue
+		}
+		unix, _ := strconv.ParseInt(fields[3], 10, 64)
+		commits = append(commits, Commit{
+			Hash:    fields[0],
+			Author:  fields[1],
+			Email:   fields[2],
+			Time:    time.Unix(unix, 0),
+			Subject: fields[4],
+		})
+	}
+	return commits, nil
+}
@@ -0,0 +1,51 @@
+package store
+
+import (
+	"ai-navigator/models"
+	"ai-navigator/utils"
+	"strconv"
+)
+
+// NewSiteID 为站点生成唯一标识：优先使用名称的拼写，其次使用网址主机名，
+// 与 taken 中已有的标识冲突时追加数字后缀
+func NewSiteID(site models.Site, taken map[string]bool) string {
+	base := utils.Slugify(site.Name)
+	if base == "" {
+		base = utils.SlugFromURL(site.URL)
+	}
+	if base == "" {
+		base = "site"
+	}
+
+	id := base
+	for i := 2; taken[id]; i++ {
+		id = base + "-" + strconv.Itoa(i)
+	}
+	taken[id] = true
+	return id
+}
+
+// assignIDs 为缺少标识的站点补全 ID，返回是否有修改
+func assignIDs(sites []models.Site, taken map[string]bool) bool {
+	changed := false
+	for i := range sites {
+		if sites[i].ID == "" {
+			sites[i].ID = NewSiteID(sites[i], taken)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// collectIDs 返回站点中已使用的标识集合
+func collectIDs(lists ...[]models.Site) map[string]bool {
+	taken := make(map[string]bool)
+	for _, sites := range lists {
+		for _, site := range sites {
+			if site.ID != "" {
+				taken[site.ID] = true
+			}
+		}
+	}
+	return taken
+}
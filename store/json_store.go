@@ -0,0 +1,421 @@
+package store
+
+import (
+	"ai-navigator/config"
+	"ai-navigator/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// JSONStore 基于 JSON 文件的存储实现
+// 数据目录中的每个 JSON、YAML 或 CSV 文件（custom.json 除外）都是一个站点包，作为上游数据按优先级合并，
+// 后台的修改全部写入 custom.json
+type JSONStore struct {
+	dataDir      string
+	packPriority []string
+	customPath   string
+	backupDir    string
+	backupKeep   int
+
+	revisionPath string
+	revMu        sync.Mutex
+	// lastRevisionID 最后一条修订记录的 ID，revisionStamp 为得到该 ID 时文件的修改时间和大小，都受 revMu 保护
+	lastRevisionID int64
+	revisionStamp  fileStamp
+
+	categoriesPath string
+	catMu          sync.Mutex
+
+	tagsPath string
+	tagMu    sync.Mutex
+
+	mu sync.Mutex
+
+	// remote 配置了远程站点目录时不为 nil，获取成功后代替本地站点包
+	remote *remoteSource
+	// git 启用 git 提交时不为 nil
+	git *gitRepo
+	// readOnly 只读取不写回，旧数据缺少的 ID 只在内存中生成，见 readOnlySource
+	readOnly bool
+
+	// overlay 最近一次读取 custom.json 得到的补丁信息，受 mu 保护，写回时使用
+	overlay overlayState
+	// cache 最近一次读取或写入后的数据，受 mu 保护，文件被外部修改时清空
+	cache *jsonState
+	// packCache 每个站点包文件上一次的解析结果，受 mu 保护，文件变化时只重新解析该文件
+	packCache map[string]packCacheEntry
+	// onWrite 写入后接收新站点列表的回调，受 mu 保护
+	onWrite func(sites []models.Site)
+
+	watch  jsonWatch
+	writer jsonWriter
+}
+
+// NewJSONStore 创建读取 cfg.DataDir 下全部站点包的存储
+// 每次写入 custom.json 前会在 backups 目录保留一份旧文件
+// 配置了远程站点目录时会先同步获取一次，失败则使用本地站点包
+func NewJSONStore(cfg config.StorageConfig) *JSONStore {
+	dataDir := cfg.DataDir
+	if dataDir == "" {
+		dataDir = "./data"
+	}
+	backupKeep := cfg.BackupKeep
+	if backupKeep <= 0 {
+		backupKeep = defaultBackupKeep
+	}
+
+	s := &JSONStore{
+		dataDir:      dataDir,
+		packPriority: cfg.Packs,
+		customPath:   filepath.Join(dataDir, customFile),
+		backupDir:    filepath.Join(dataDir, "backups"),
+		backupKeep:   backupKeep,
+
+		revisionPath: filepath.Join(dataDir, "revisions.jsonl"),
+
+		categoriesPath: filepath.Join(dataDir, categoriesFile),
+		tagsPath:       filepath.Join(dataDir, tagsFile),
+	}
+
+	if cfg.GitCommit {
+		repo, err := openGitRepo(dataDir)
+		if err != nil {
+			log.Printf("无法启用 git 提交: %v", err)
+		} else {
+			s.git = repo
+		}
+	}
+
+	if cfg.Remote.URL != "" {
+		s.remote = newRemoteSource(cfg.Remote)
+		if _, err := s.remote.fetch(); err != nil {
+			log.Printf("获取远程站点目录失败，暂时使用本地数据: %v", err)
+		}
+	}
+	return s
+}
+
+// readOnlySource 返回只读的 JSONStore，沿用配置中的站点包优先级，不拉取远程站点目录，也不改写站点包和 custom.json
+// 用于为 SQLite 存储导入初始数据和命令行导出
+func readOnlySource(cfg config.StorageConfig) *JSONStore {
+	cfg.GitCommit = false
+	cfg.Remote = config.RemoteConfig{}
+	s := NewJSONStore(cfg)
+	s.readOnly = true
+	return s
+}
+
+func (s *JSONStore) List() ([]models.Site, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upstream, customSites, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return MergeSites(upstream, customSites), nil
+}
+
+func (s *JSONStore) Get(id string) (models.Site, error) {
+	sites, err := s.List()
+	if err != nil {
+		return models.Site{}, err
+	}
+	if i := indexOf(sites, id); i >= 0 {
+		return sites[i], nil
+	}
+	return models.Site{}, ErrNotFound
+}
+
+func (s *JSONStore) Create(site models.Site) (models.Site, error) {
+	err := s.mutate(func(sites []models.Site, taken map[string]bool) ([]models.Site, error) {
+		if site.ID == "" {
+			site.ID = NewSiteID(site, taken)
+		} else if indexOf(sites, site.ID) >= 0 {
+			return nil, ErrExists
+		}
+		return append(sites, site), nil
+	})
+	return site, err
+}
+
+func (s *JSONStore) Update(id string, site models.Site) error {
+	return s.mutate(func(sites []models.Site, taken map[string]bool) ([]models.Site, error) {
+		i := indexOf(sites, id)
+		if i < 0 {
+			return nil, ErrNotFound
+		}
+		site.ID = id
+		sites[i] = site
+		return sites, nil
+	})
+}
+
+func (s *JSONStore) UpdateVersion(id, version string, site models.Site) (models.Site, error) {
+	var latest models.Site
+	err := s.mutate(func(sites []models.Site, taken map[string]bool) ([]models.Site, error) {
+		i := indexOf(sites, id)
+		if i < 0 {
+			return nil, ErrNotFound
+		}
+		if sites[i].Version() != version {
+			latest = sites[i]
+			return nil, ErrStale
+		}
+		site.ID = id
+		sites[i] = site
+		return sites, nil
+	})
+	if errors.Is(err, ErrStale) {
+		return latest, err
+	}
+	site.ID = id
+	return site, err
+}
+
+func (s *JSONStore) Delete(id, actor string) error {
+	return s.mutate(func(sites []models.Site, taken map[string]bool) ([]models.Site, error) {
+		i := indexOf(sites, id)
+		if i < 0 {
+			return nil, ErrNotFound
+		}
+		markDeleted(&sites[i], actor, time.Now())
+		return sites, nil
+	})
+}
+
+func (s *JSONStore) SaveAll(batch []models.Site) ([]models.Site, error) {
+	saved := make([]models.Site, len(batch))
+	err := s.mutate(func(sites []models.Site, taken map[string]bool) ([]models.Site, error) {
+		for i, site := range batch {
+			if site.ID == "" {
+				site.ID = NewSiteID(site, taken)
+			}
+			if j := indexOf(sites, site.ID); j >= 0 {
+				sites[j] = site
+			} else {
+				taken[site.ID] = true
+				sites = append(sites, site)
+			}
+			saved[i] = site
+		}
+		return sites, nil
+	})
+	return saved, err
+}
+
+// mutate 在合并后的站点列表上执行修改并写回 custom.json
+// taken 包含所有已使用的 ID（含已删除站点），供新建站点时避免冲突
+// 之前删除的站点以 deleted 标记保留，避免站点包中的同 ID 站点重新出现
+func (s *JSONStore) mutate(fn func(sites []models.Site, taken map[string]bool) ([]models.Site, error)) error {
+	return s.submit(func(upstream, customSites []models.Site) ([]models.Site, error) {
+		next, err := fn(MergeSites(upstream, customSites), collectIDs(upstream, customSites))
+		if err != nil {
+			return nil, err
+		}
+		next = dropUnchanged(next, upstream)
+		for _, site := range customSites {
+			if site.Deleted && indexOf(next, site.ID) < 0 {
+				next = append(next, site)
+			}
+		}
+		return next, nil
+	})
+}
+
+// read 读取合并后的上游站点和自定义站点，custom.json 不存在时视为空
+// custom.json 中覆盖上游的字段补丁会应用到上游站点上，返回的自定义站点都是完整的站点
+// 远程站点目录获取成功时以它作为上游，否则使用本地站点包
+// 任一文件存在语法或字段错误时返回 ValidationErrors，不会写回任何文件
+// 缓存有效时直接返回缓存的副本，custom.json 被其它程序改写过时重新加载，调用方需持有 s.mu
+func (s *JSONStore) read() ([]models.Site, []models.Site, error) {
+	if c := s.cache; c != nil && c.stamp.equal(s.customStamp()) {
+		s.overlay = c.overlay
+		return cloneSites(c.upstream), cloneSites(c.customSites), nil
+	}
+
+	stamp := s.customStamp()
+	packs, errs, err := s.upstreamPacks()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var customSites []models.Site
+	var entries []json.RawMessage
+	customData, err := os.ReadFile(s.customPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("读取 custom.json 文件失败: %w", err)
+	}
+	if err == nil {
+		customSites, err = decodeSitesJSON(customFile, customData)
+		var decodeErrs ValidationErrors
+		if errors.As(err, &decodeErrs) {
+			errs = append(errs, decodeErrs...)
+		} else if err != nil {
+			return nil, nil, err
+		} else if err := json.Unmarshal(customData, &entries); err != nil {
+			return nil, nil, fmt.Errorf("解析 custom.json 文件失败: %w", err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	if err := s.migrateIDs(packs, customSites); err != nil {
+		return nil, nil, err
+	}
+
+	// 补丁应用到站点包上之后才是完整的站点，校验放在最后
+	upstream := mergePacks(packs)
+	customSites, s.overlay, err = applyPatches(upstream, customSites, entries)
+	if err != nil {
+		return nil, nil, err
+	}
+	if errs := validateSites(customFile, customSites); len(errs) > 0 {
+		return nil, nil, errs
+	}
+	s.cache = &jsonState{upstream: upstream, customSites: customSites, overlay: s.overlay, stamp: stamp}
+	return cloneSites(upstream), cloneSites(customSites), nil
+}
+
+// migrateIDs 为旧数据补全站点 ID 并写回文件，只在首次加载旧数据时生效
+// 每个站点包内单独分配 ID，不同包中的同名站点会得到相同的 ID，从而按优先级去重
+// 旧版 custom.json 通过名称覆盖上游站点，迁移时沿用对应站点的 ID；只读时不写回文件
+func (s *JSONStore) migrateIDs(packs []sitePack, customSites []models.Site) error {
+	for _, pack := range packs {
+		if assignIDs(pack.Sites, collectIDs(pack.Sites)) && !s.readOnly {
+			if err := s.writeSites(pack.Path, pack.Sites); err != nil {
+				return err
+			}
+			log.Printf("已为 %s 中的站点生成 ID", pack.Name)
+		}
+	}
+
+	byName := make(map[string]string)
+	for _, pack := range packs {
+		for _, site := range pack.Sites {
+			if _, ok := byName[site.Name]; !ok {
+				byName[site.Name] = site.ID
+			}
+		}
+	}
+
+	taken := collectIDs(customSites)
+	for _, pack := range packs {
+		for id := range collectIDs(pack.Sites) {
+			taken[id] = true
+		}
+	}
+
+	changed := false
+	for i := range customSites {
+		if customSites[i].ID != "" {
+			continue
+		}
+		if id, ok := byName[customSites[i].Name]; ok && indexOf(customSites, id) < 0 {
+			customSites[i].ID = id
+		} else {
+			customSites[i].ID = NewSiteID(customSites[i], taken)
+		}
+		changed = true
+	}
+	if changed && !s.readOnly {
+		data, err := json.MarshalIndent(customSites, "", "    ")
+		if err != nil {
+			return fmt.Errorf("序列化JSON失败: %w", err)
+		}
+		if err := s.writeCustom(data); err != nil {
+			return err
+		}
+		log.Printf("已为 custom.json 中的站点生成 ID")
+	}
+	return nil
+}
+
+// writeSites 按文件扩展名对应的格式原子写入站点列表
+func (s *JSONStore) writeSites(path string, sites []models.Site) error {
+	data, err := EncodeSites(FormatOf(path), sites)
+	if err != nil {
+		return err
+	}
+	if err := s.writeFile(path, data); err != nil {
+		return fmt.Errorf("写入 %s 文件失败: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// MergeSites 合并上游站点和自定义站点，ID 相同时以自定义站点为准
+// 覆盖上游的自定义站点保留上游的来源并标记为已修改，仅存在于 custom.json 的站点来源为 custom.json
+func MergeSites(upstream, customSites []models.Site) []models.Site {
+	upstreamPack := make(map[string]string, len(upstream))
+	for _, site := range upstream {
+		upstreamPack[site.ID] = site.Pack
+	}
+
+	customIDs := make(map[string]bool, len(customSites))
+	for _, site := range customSites {
+		customIDs[site.ID] = true
+	}
+
+	result := make([]models.Site, 0, len(upstream)+len(customSites))
+
+	for _, site := range customSites {
+		if site.Deleted {
+			continue
+		}
+		if pack, ok := upstreamPack[site.ID]; ok {
+			site.Pack = pack
+			site.Overridden = true
+		} else {
+			site.Pack = customFile
+		}
+		result = append(result, site)
+	}
+
+	for _, site := range upstream {
+		if !customIDs[site.ID] {
+			result = append(result, site)
+		}
+	}
+
+	return result
+}
+
+// dropUnchanged 去掉与上游完全相同的站点，custom.json 只保存真正修改过的站点
+func dropUnchanged(sites, upstream []models.Site) []models.Site {
+	byID := make(map[string]int, len(upstream))
+	for i, site := range upstream {
+		byID[site.ID] = i
+	}
+
+	result := make([]models.Site, 0, len(sites))
+	for _, site := range sites {
+		if i, ok := byID[site.ID]; ok && sameSite(site, upstream[i]) {
+			continue
+		}
+		result = append(result, site)
+	}
+	return result
+}
+
+// sameSite 比较两个站点写入文件的内容是否一致，空标签与 nil 视为相同
+// 每次保存都要与全部上游站点比较，使用内容哈希而不是序列化后比较
+func sameSite(a, b models.Site) bool {
+	return a.ContentHash() == b.ContentHash()
+}
+
+func indexOf(sites []models.Site, id string) int {
+	for i, site := range sites {
+		if site.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,278 @@
+package store
+
+import (
+	"ai-navigator/models"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Overlay 以字段补丁覆盖站点包的存储实现
+// custom.json 中覆盖站点包的记录只保存后台修改过的字段，加载时应用到站点包的数据上，站点包后续对其它字段的更新仍会生效
+type Overlay interface {
+	// Upstream 返回站点在站点包中的原始数据，站点只存在于 custom.json 时返回 ErrNotFound
+	Upstream(id string) (models.Site, error)
+	// ResetFields 撤销站点对指定字段的修改，恢复为站点包中的值，返回恢复后的站点
+	ResetFields(id string, fields ...string) (models.Site, error)
+}
+
+// ErrUnknownField 字段名不存在或不能恢复
+var ErrUnknownField = errors.New("未知的站点字段")
+
+// patchKeepFields 补丁中始终保留的字段，不属于可以恢复的站点内容
+var patchKeepFields = map[string]bool{"id": true, "deleted": true, "deleted_at": true, "deleted_by": true}
+
+func (s *JSONStore) Upstream(id string) (models.Site, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upstream, _, err := s.read()
+	if err != nil {
+		return models.Site{}, err
+	}
+	if i := indexOf(upstream, id); i >= 0 {
+		return upstream[i], nil
+	}
+	return models.Site{}, ErrNotFound
+}
+
+func (s *JSONStore) ResetFields(id string, fields ...string) (models.Site, error) {
+	for _, field := range fields {
+		if _, ok := models.SiteFieldIndex(field); !ok || patchKeepFields[field] {
+			return models.Site{}, fmt.Errorf("%w: %s", ErrUnknownField, field)
+		}
+	}
+
+	var reset models.Site
+	err := s.mutateCustom(func(upstream, customSites []models.Site) ([]models.Site, error) {
+		u := indexOf(upstream, id)
+		if u < 0 {
+			return nil, ErrNotFound
+		}
+		i := indexOf(customSites, id)
+		if i < 0 {
+			// 没有任何修改，已经与站点包一致
+			reset = upstream[u]
+			return nil, errNoChange
+		}
+		if customSites[i].Deleted {
+			return nil, ErrNotFound
+		}
+
+		site := reflect.ValueOf(&customSites[i]).Elem()
+		up := reflect.ValueOf(upstream[u])
+		for _, field := range fields {
+			j, _ := models.SiteFieldIndex(field)
+			site.Field(j).Set(up.Field(j))
+		}
+		reset = customSites[i]
+		return customSites, nil
+	})
+	if errors.Is(err, errNoChange) {
+		return reset, nil
+	}
+	return reset, err
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// overlayState custom.json 中除站点内容以外的补丁信息，写回时需要原样保留
+type overlayState struct {
+	// bases 每条覆盖记录中被修改的字段在修改时站点包中的值，键为站点 ID 和 JSON 字段名
+	bases map[string]map[string]json.RawMessage
+	// orphans 站点包中已不存在的站点的补丁，原样保留，站点包重新加入该站点后继续生效
+	orphans []json.RawMessage
+}
+
+// patchEntry custom.json 记录中用于识别补丁的部分
+type patchEntry struct {
+	ID   string                     `json:"id"`
+	Name *string                    `json:"name"`
+	Base map[string]json.RawMessage `json:"base"`
+}
+
+// applyPatches 将 custom.json 中的记录应用到站点包的数据上，返回完整的站点
+// entries 与 customSites 一一对应，是同一份 JSON 中每条记录的原始内容：
+// 覆盖站点包的记录只替换其中出现的字段，其余记录本身就是完整的站点；
+// 没有 name 字段、对应的站点又不在站点包中的记录是失效的补丁，不参与加载
+func applyPatches(upstream, customSites []models.Site, entries []json.RawMessage) ([]models.Site, overlayState, error) {
+	byID := make(map[string]int, len(upstream))
+	for i, site := range upstream {
+		byID[site.ID] = i
+	}
+
+	state := overlayState{bases: make(map[string]map[string]json.RawMessage)}
+	result := make([]models.Site, 0, len(customSites))
+	for i, site := range customSites {
+		if i >= len(entries) {
+			result = append(result, site)
+			continue
+		}
+		var entry patchEntry
+		if err := json.Unmarshal(entries[i], &entry); err != nil {
+			return nil, overlayState{}, fmt.Errorf("解析 custom.json 第 %d 条记录失败: %w", i+1, err)
+		}
+
+		u, ok := byID[site.ID]
+		if !ok {
+			if entry.Name == nil {
+				state.orphans = append(state.orphans, entries[i])
+				continue
+			}
+			result = append(result, site)
+			continue
+		}
+
+		patched := upstream[u]
+		patched.Pack = ""
+		if err := applyFields(&patched, entries[i]); err != nil {
+			return nil, overlayState{}, fmt.Errorf("解析 custom.json 第 %d 条记录失败: %w", i+1, err)
+		}
+		// 旧数据的 ID 可能是加载时迁移生成的
+		patched.ID = site.ID
+		if patched.Deleted && !inTrash(patched) {
+			patched = purgedSite(site.ID)
+		}
+		if len(entry.Base) > 0 {
+			state.bases[site.ID] = entry.Base
+		}
+		result = append(result, patched)
+	}
+	return result, state, nil
+}
+
+// applyFields 将补丁中出现的字段写到 site 上，其余字段保持不变
+// 每个字段解码为新的值后整体替换，不会改到与站点包共用的标签切片和定价
+func applyFields(site *models.Site, entry json.RawMessage) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(entry, &fields); err != nil {
+		return err
+	}
+	v := reflect.ValueOf(site).Elem()
+	for name, raw := range fields {
+		i, ok := models.SiteFieldIndex(name)
+		if !ok {
+			continue
+		}
+		value := reflect.New(v.Field(i).Type())
+		if err := json.Unmarshal(raw, value.Interface()); err != nil {
+			return fmt.Errorf("字段 %s: %w", name, err)
+		}
+		v.Field(i).Set(value.Elem())
+	}
+	return nil
+}
+
+// encodeCustom 序列化 custom.json：覆盖站点包的站点只写入与站点包不同的字段，其余站点写入全部字段
+// 已彻底删除的站点同样写入全部（已清空的）字段，避免应用到站点包上后重新出现内容
+func encodeCustom(sites, upstream []models.Site, state overlayState) ([]byte, error) {
+	byID := make(map[string]int, len(upstream))
+	for i, site := range upstream {
+		byID[site.ID] = i
+	}
+
+	entries := make([]json.RawMessage, len(sites), len(sites)+len(state.orphans))
+	for i, site := range sites {
+		var err error
+		if u, ok := byID[site.ID]; ok && (!site.Deleted || inTrash(site)) {
+			entries[i], err = encodePatch(site, upstream[u], state.bases[site.ID])
+		} else {
+			entries[i], err = json.Marshal(site)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("序列化JSON失败: %w", err)
+		}
+	}
+	entries = append(entries, state.orphans...)
+
+	data, err := json.MarshalIndent(entries, "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化JSON失败: %w", err)
+	}
+	return data, nil
+}
+
+// encodePatch 按 models.Site 的字段顺序写出 id 和与 up 不同的字段，
+// 并在 base 中记录这些字段修改时站点包中的值：已有记录的沿用 base，新修改的字段取 up 当前的值
+func encodePatch(site, up models.Site, base map[string]json.RawMessage) (json.RawMessage, error) {
+	var patch, nextBase fieldWriter
+	v, u := reflect.ValueOf(site), reflect.ValueOf(up)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := jsonFieldName(t.Field(i))
+		if name == "" {
+			continue
+		}
+		value, err := fieldJSON(v.Field(i))
+		if err != nil {
+			return nil, err
+		}
+		if name == "id" {
+			patch.write(name, value)
+			continue
+		}
+		upValue, err := fieldJSON(u.Field(i))
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(value, upValue) {
+			continue
+		}
+		patch.write(name, value)
+		if patchKeepFields[name] {
+			continue
+		}
+		if old, ok := base[name]; ok {
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, old); err == nil {
+				upValue = compact.Bytes()
+			}
+		}
+		nextBase.write(name, upValue)
+	}
+	if nextBase.buf.Len() > 0 {
+		patch.write("base", nextBase.bytes())
+	}
+	return patch.bytes(), nil
+}
+
+// fieldWriter 按写入顺序拼接 JSON 对象
+type fieldWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *fieldWriter) write(name string, value []byte) {
+	if w.buf.Len() == 0 {
+		w.buf.WriteByte('{')
+	} else {
+		w.buf.WriteByte(',')
+	}
+	key, _ := json.Marshal(name)
+	w.buf.Write(key)
+	w.buf.WriteByte(':')
+	w.buf.Write(value)
+}
+
+func (w *fieldWriter) bytes() []byte {
+	if w.buf.Len() == 0 {
+		return []byte("{}")
+	}
+	return append(w.buf.Bytes(), '}')
+}
+
+// fieldJSON 序列化单个字段，空切片与 nil 视为相同
+func fieldJSON(v reflect.Value) ([]byte, error) {
+	if v.Kind() == reflect.Slice && v.Len() == 0 {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(v.Interface())
+}
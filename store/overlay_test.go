@@ -0,0 +1,177 @@
+package store
+
+import (
+	"ai-navigator/config"
+	"ai-navigator/models"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func overlayUpstream() models.Site {
+	return models.Site{
+		ID:          "chat",
+		Name:        "Chat",
+		URL:         "https://chat.example.com/",
+		Description: "对话助手",
+		Tags:        []string{"AI对话"},
+		Category:    "AI对话",
+		Rating:      4.5,
+		Pricing: &models.Pricing{
+			Model: models.PricingFreemium,
+			Plans: []models.PricingPlan{{Name: "Pro", Price: 20, Currency: "USD", Period: models.BillingMonthly}},
+		},
+	}
+}
+
+// patchKeys 返回补丁和其中 base 的字段名
+func patchKeys(t *testing.T, patch json.RawMessage) (keys, baseKeys []string) {
+	t.Helper()
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &fields); err != nil {
+		t.Fatalf("补丁不是 JSON 对象: %v", err)
+	}
+	var base map[string]json.RawMessage
+	if raw, ok := fields["base"]; ok {
+		if err := json.Unmarshal(raw, &base); err != nil {
+			t.Fatalf("base 不是 JSON 对象: %v", err)
+		}
+	}
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	for k := range base {
+		baseKeys = append(baseKeys, k)
+	}
+	slices.Sort(keys)
+	slices.Sort(baseKeys)
+	return keys, baseKeys
+}
+
+func TestEncodePatchOnlyChangedFields(t *testing.T) {
+	up := overlayUpstream()
+	site := up
+	site.Name = "Chat Plus"
+	site.Tags = []string{"AI对话", "写作"}
+
+	patch, err := encodePatch(site, up, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, baseKeys := patchKeys(t, patch)
+	if want := []string{"base", "id", "name", "tags"}; !slices.Equal(keys, want) {
+		t.Fatalf("补丁字段 = %v，应为 %v", keys, want)
+	}
+	if want := []string{"name", "tags"}; !slices.Equal(baseKeys, want) {
+		t.Fatalf("base 字段 = %v，应为 %v", baseKeys, want)
+	}
+
+	var base struct {
+		Base struct {
+			Name string `json:"name"`
+		} `json:"base"`
+	}
+	json.Unmarshal(patch, &base)
+	if base.Base.Name != up.Name {
+		t.Fatalf("base.name = %q，应为修改时站点包中的值 %q", base.Base.Name, up.Name)
+	}
+
+	// 与站点包相同时只有 id
+	patch, err = encodePatch(up, up, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := patchKeys(t, patch); !slices.Equal(keys, []string{"id"}) {
+		t.Fatalf("未修改的站点补丁字段 = %v，应只有 id", keys)
+	}
+}
+
+func TestApplyPatchesRoundTrip(t *testing.T) {
+	upstream := []models.Site{overlayUpstream()}
+	edited := upstream[0]
+	edited.Name = "Chat Plus"
+	edited.Tags = []string{"写作"}
+	edited.Pricing = &models.Pricing{Model: models.PricingPaid}
+
+	data, err := encodeCustom([]models.Site{edited}, upstream, overlayState{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var customSites []models.Site
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &customSites); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatal(err)
+	}
+
+	// 站点包随后更新了没有修改过的字段
+	upstream[0].Description = "新的描述"
+	want := edited
+	want.Description = "新的描述"
+
+	result, state, err := applyPatches(upstream, customSites, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || !reflect.DeepEqual(result[0], want) {
+		t.Fatalf("应用补丁得到 %+v，应为 %+v", result, want)
+	}
+	if _, ok := state.bases["chat"]["name"]; !ok {
+		t.Fatalf("没有读取补丁中的 base: %+v", state.bases)
+	}
+
+	// 站点包的数据不受补丁影响
+	if up := upstream[0]; up.Tags[0] != "AI对话" || up.Pricing.Model != models.PricingFreemium || len(up.Pricing.Plans) != 1 {
+		t.Fatalf("应用补丁改动了站点包的数据: %+v %+v", up, up.Pricing)
+	}
+}
+
+func TestResetFieldsFallsBackToUpstream(t *testing.T) {
+	dir := t.TempDir()
+	pack, err := json.Marshal([]models.Site{overlayUpstream()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ai.json"), pack, 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := NewJSONStore(config.StorageConfig{DataDir: dir})
+	defer s.Close()
+
+	edited := overlayUpstream()
+	edited.Name = "Chat Plus"
+	edited.Description = "改过的描述"
+	if err := s.Update("chat", edited); err != nil {
+		t.Fatal(err)
+	}
+
+	reset, err := s.ResetFields("chat", "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reset.Name != "Chat" || reset.Description != "改过的描述" {
+		t.Fatalf("恢复后 name = %q, description = %q，应为站点包的名称和修改后的描述", reset.Name, reset.Description)
+	}
+
+	got, err := s.Get("chat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "Chat" {
+		t.Fatalf("重新读取的名称 = %q，应为站点包中的 Chat", got.Name)
+	}
+	custom, err := os.ReadFile(filepath.Join(dir, customFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []json.RawMessage
+	json.Unmarshal(custom, &entries)
+	if keys, baseKeys := patchKeys(t, entries[0]); slices.Contains(keys, "name") || slices.Contains(baseKeys, "name") {
+		t.Fatalf("恢复后的补丁仍包含 name: %s", entries[0])
+	}
+}
@@ -0,0 +1,166 @@
+package store
+
+import (
+	"ai-navigator/models"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// customFile 后台修改写入的文件，不作为站点包加载
+const customFile = "custom.json"
+
+// reservedFiles 数据目录中不属于站点包的文件
+var reservedFiles = map[string]bool{
+	customFile:     true,
+	categoriesFile: true,
+	tagsFile:       true,
+}
+
+// sitePack 数据目录中的一个站点包文件，例如 ai.json、coding.yaml、design.csv
+type sitePack struct {
+	Name  string
+	Path  string
+	Sites []models.Site
+}
+
+// PackLister 由多个站点包组成数据的存储实现
+type PackLister interface {
+	// Packs 按优先级从高到低返回站点包文件名
+	Packs() ([]string, error)
+}
+
+func (s *JSONStore) Packs() ([]string, error) {
+	if s.remote != nil {
+		if pack, ok := s.remote.pack(); ok {
+			return []string{pack.Name}, nil
+		}
+	}
+	return s.packFiles()
+}
+
+// upstreamPacks 返回作为上游数据的站点包：远程站点目录获取成功时只使用远程数据，否则读取本地站点包
+func (s *JSONStore) upstreamPacks() ([]sitePack, ValidationErrors, error) {
+	if s.remote != nil {
+		if pack, ok := s.remote.pack(); ok {
+			return []sitePack{pack}, nil, nil
+		}
+	}
+	return s.readPacks()
+}
+
+// packFiles 按优先级返回数据目录中的站点包文件名：
+// 配置中列出的站点包按配置顺序排在前面，其余按文件名排序
+func (s *JSONStore) packFiles() ([]string, error) {
+	entries, err := os.ReadDir(s.dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("读取数据目录失败: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if s.isPackFile(entry.Name()) && !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("数据目录 %s 中没有站点数据文件", s.dataDir)
+	}
+
+	rank := func(name string) int {
+		for i, p := range s.packPriority {
+			if p == name {
+				return i
+			}
+		}
+		return len(s.packPriority)
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		ri, rj := rank(names[i]), rank(names[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return names[i] < names[j]
+	})
+	return names, nil
+}
+
+// isPackFile 判断数据目录中的文件是否为站点包
+func (s *JSONStore) isPackFile(name string) bool {
+	return FormatOf(name) != "" && !strings.HasPrefix(name, ".") && !reservedFiles[name]
+}
+
+// readPacks 读取并校验全部站点包，收集所有文件中的错误后一起返回
+// 修改时间和大小没有变化的站点包直接使用上一次的解析结果，只重新解析变化的文件，调用方需持有 s.mu
+func (s *JSONStore) readPacks() ([]sitePack, ValidationErrors, error) {
+	names, err := s.packFiles()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	next := make(map[string]packCacheEntry, len(names))
+	var packs []sitePack
+	var errs ValidationErrors
+	for _, name := range names {
+		path := filepath.Join(s.dataDir, name)
+		stamp := statFile(path)
+		entry, ok := s.packCache[name]
+		if !ok || !entry.stamp.equal(stamp) {
+			sites, err := readPackFile(name, path)
+			var decodeErrs ValidationErrors
+			if errors.As(err, &decodeErrs) {
+				errs = append(errs, decodeErrs...)
+				continue
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			entry = packCacheEntry{stamp: stamp, sites: sites, errs: validateSites(name, sites)}
+		}
+		next[name] = entry
+
+		errs = append(errs, entry.errs...)
+		packs = append(packs, sitePack{Name: name, Path: path, Sites: entry.sites})
+	}
+	s.packCache = next
+	return packs, errs, nil
+}
+
+// packCacheEntry 站点包上一次解析和校验的结果
+type packCacheEntry struct {
+	stamp fileStamp
+	sites []models.Site
+	errs  ValidationErrors
+}
+
+// readPackFile 解析站点包文件，JSON 文件逐条流式解析，其它格式读入后整体解析
+func readPackFile(name, path string) ([]models.Site, error) {
+	if FormatOf(name) == FormatJSON {
+		return decodeSitesJSONFile(name, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 文件失败: %w", name, err)
+	}
+	return decodeSites(name, data)
+}
+
+// mergePacks 按优先级合并站点包，多个包中 ID 相同的站点只保留优先级最高的一个
+func mergePacks(packs []sitePack) []models.Site {
+	seen := make(map[string]bool)
+	var result []models.Site
+	for _, pack := range packs {
+		for _, site := range pack.Sites {
+			if seen[site.ID] {
+				continue
+			}
+			seen[site.ID] = true
+			site.Pack = pack.Name
+			result = append(result, site)
+		}
+	}
+	return result
+}
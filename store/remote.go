@@ -0,0 +1,233 @@
+package store
+
+import (
+	"ai-navigator/config"
+	"ai-navigator/models"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRemoteInterval = 5 * time.Minute
+	defaultRemoteTimeout  = 10 * time.Second
+
+	// maxRemoteSize 远程站点目录的大小上限
+	maxRemoteSize = 32 << 20
+)
+
+// RemoteStatus 远程站点目录的同步状态
+type RemoteStatus struct {
+	URL string
+	// LastFetch 最近一次请求的时间
+	LastFetch time.Time
+	// LastUpdate 最近一次获取到新数据的时间，为零表示尚未成功获取，正在使用本地数据
+	LastUpdate time.Time
+	ETag       string
+	SiteCount  int
+	// Err 最近一次请求失败或数据无效的原因
+	Err error
+}
+
+// RemoteReporter 支持远程站点目录的存储实现
+type RemoteReporter interface {
+	// RemoteStatus 返回远程站点目录的同步状态，未配置时 ok 为 false
+	RemoteStatus() (status RemoteStatus, ok bool)
+}
+
+// remoteSource 定期拉取的远程站点目录
+// 通过 ETag/Last-Modified 跳过未变化的数据，新数据校验通过后才会替换
+type remoteSource struct {
+	url      string
+	interval time.Duration
+	client   *http.Client
+
+	// fetchMu 保证同一时间只有一个请求，mu 保护下面的数据
+	fetchMu      sync.Mutex
+	mu           sync.Mutex
+	etag         string
+	lastModified string
+	sites        []models.Site
+	status       RemoteStatus
+}
+
+func newRemoteSource(cfg config.RemoteConfig) *remoteSource {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultRemoteInterval
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultRemoteTimeout
+	}
+
+	return &remoteSource{
+		url:      cfg.URL,
+		interval: interval,
+		client:   &http.Client{Timeout: timeout},
+		status:   RemoteStatus{URL: cfg.URL},
+	}
+}
+
+// fetch 请求远程站点目录，返回数据是否发生变化
+// 请求失败或数据无效时保留上一次的数据并返回错误，请求期间不阻塞读取
+func (r *remoteSource) fetch() (bool, error) {
+	r.fetchMu.Lock()
+	defer r.fetchMu.Unlock()
+
+	r.mu.Lock()
+	etag, lastModified, fetched := r.etag, r.lastModified, r.sites != nil
+	r.mu.Unlock()
+
+	result, err := r.request(etag, lastModified, fetched)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status.LastFetch = time.Now()
+	r.status.Err = err
+	if result == nil {
+		return false, err
+	}
+	r.sites = result.sites
+	r.etag = result.etag
+	r.lastModified = result.lastModified
+	r.status.LastUpdate = r.status.LastFetch
+	r.status.ETag = result.etag
+	r.status.SiteCount = len(result.sites)
+	return true, nil
+}
+
+// remoteResult 一次成功获取到的新数据
+type remoteResult struct {
+	sites        []models.Site
+	etag         string
+	lastModified string
+}
+
+// request 发起条件请求，数据未变化时返回 nil
+func (r *remoteSource) request(etag, lastModified string, conditional bool) (*remoteResult, error) {
+	req, err := http.NewRequest(http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("远程站点目录地址无效: %w", err)
+	}
+	if conditional {
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求远程站点目录失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("远程站点目录返回 %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取远程站点目录失败: %w", err)
+	}
+	if len(data) > maxRemoteSize {
+		return nil, fmt.Errorf("远程站点目录超过 %d MB", maxRemoteSize>>20)
+	}
+
+	file := r.fileName(resp.Header.Get("Content-Type"))
+	sites, err := decodeSites(file, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(sites) == 0 {
+		return nil, fmt.Errorf("远程站点目录为空")
+	}
+	if errs := validateSites(file, sites); len(errs) > 0 {
+		return nil, errs
+	}
+	// 远程数据无法写回，缺少 ID 的站点只在内存中生成
+	assignIDs(sites, collectIDs(sites))
+
+	return &remoteResult{
+		sites:        sites,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// fileName 远程站点目录在校验错误和站点来源中显示的文件名，格式按扩展名识别，
+// 扩展名无法识别时根据 Content-Type 判断，默认为 JSON
+func (r *remoteSource) fileName(contentType string) string {
+	name := "remote.json"
+	if u, err := url.Parse(r.url); err == nil {
+		if base := path.Base(u.Path); FormatOf(base) != "" {
+			return base
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		name = "remote.yaml"
+	case "text/csv":
+		name = "remote.csv"
+	}
+	return name
+}
+
+// pack 返回最近一次获取成功的数据，尚未成功获取时 ok 为 false
+func (r *remoteSource) pack() (sitePack, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.sites == nil {
+		return sitePack{}, false
+	}
+	sites := make([]models.Site, len(r.sites))
+	copy(sites, r.sites)
+	return sitePack{Name: r.url, Sites: sites}, true
+}
+
+// poll 按间隔拉取远程站点目录，数据变化时回调 onChange
+func (r *remoteSource) poll(stop chan struct{}, onChange func()) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			changed, err := r.fetch()
+			if err != nil {
+				log.Printf("同步远程站点目录失败，继续使用上一次的数据: %v", err)
+				continue
+			}
+			if changed {
+				log.Println("远程站点目录已更新，重新加载数据")
+				onChange()
+			}
+		}
+	}
+}
+
+func (s *JSONStore) RemoteStatus() (RemoteStatus, bool) {
+	if s.remote == nil {
+		return RemoteStatus{}, false
+	}
+	s.remote.mu.Lock()
+	defer s.remote.mu.Unlock()
+	return s.remote.status, true
+}
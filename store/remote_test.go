@@ -0,0 +1,133 @@
+package store
+
+import (
+	"ai-navigator/config"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+const (
+	remoteCatalogV1 = `[{"id": "remote-a", "name": "Remote A", "url": "https://a.example.com/"}]`
+	localCatalog    = `[{"id": "local-a", "name": "Local A", "url": "https://local.example.com/"}]`
+)
+
+// catalogServer 模拟远程站点目录，body 和 etag 可以在测试中途修改
+type catalogServer struct {
+	mu   sync.Mutex
+	body string
+	etag string
+	// notModified 返回 304 的次数
+	notModified int
+}
+
+func (c *catalogServer) set(body, etag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.body, c.etag = body, etag
+}
+
+func (c *catalogServer) notModifiedCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.notModified
+}
+
+func (c *catalogServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.etag != "" && r.Header.Get("If-None-Match") == c.etag {
+		c.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", c.etag)
+	w.Write([]byte(c.body))
+}
+
+// newRemoteTestStore 创建以 url 为远程站点目录的存储，数据目录中放一个本地站点包
+func newRemoteTestStore(t *testing.T, url string) *JSONStore {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "local.json"), []byte(localCatalog), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return NewJSONStore(config.StorageConfig{DataDir: dir, Remote: config.RemoteConfig{URL: url}})
+}
+
+func listIDs(t *testing.T, s *JSONStore) []string {
+	t.Helper()
+	sites, err := s.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	ids := make([]string, len(sites))
+	for i, site := range sites {
+		ids[i] = site.ID
+	}
+	return ids
+}
+
+func TestRemoteSource(t *testing.T) {
+	catalog := &catalogServer{body: remoteCatalogV1, etag: `"v1"`}
+	srv := httptest.NewServer(catalog)
+	defer srv.Close()
+
+	s := newRemoteTestStore(t, srv.URL+"/catalog.json")
+
+	t.Run("首次获取记录 ETag", func(t *testing.T) {
+		if ids := listIDs(t, s); len(ids) != 1 || ids[0] != "remote-a" {
+			t.Fatalf("站点 = %v，应为远程站点目录中的 remote-a", ids)
+		}
+		status, ok := s.RemoteStatus()
+		if !ok || status.ETag != `"v1"` || status.SiteCount != 1 || status.Err != nil {
+			t.Fatalf("同步状态 = %+v", status)
+		}
+	})
+
+	t.Run("304 沿用缓存的数据", func(t *testing.T) {
+		changed, err := s.remote.fetch()
+		if err != nil || changed {
+			t.Fatalf("fetch() = %v, %v，应为 false, nil", changed, err)
+		}
+		if n := catalog.notModifiedCount(); n != 1 {
+			t.Fatalf("服务端返回 304 %d 次，应为 1 次", n)
+		}
+		if ids := listIDs(t, s); len(ids) != 1 || ids[0] != "remote-a" {
+			t.Fatalf("站点 = %v，应沿用 remote-a", ids)
+		}
+	})
+
+	t.Run("数据无效时保留上一次的数据", func(t *testing.T) {
+		catalog.set(`[{"id": "remote-b", "name": "Remote B", "url": "not a url"}]`, `"v2"`)
+		changed, err := s.remote.fetch()
+		if err == nil || changed {
+			t.Fatalf("fetch() = %v, %v，应返回校验错误", changed, err)
+		}
+		if ids := listIDs(t, s); len(ids) != 1 || ids[0] != "remote-a" {
+			t.Fatalf("站点 = %v，应保留 remote-a", ids)
+		}
+		status, _ := s.RemoteStatus()
+		if status.Err == nil || status.ETag != `"v1"` {
+			t.Fatalf("同步状态 = %+v，应记录错误并保留 ETag v1", status)
+		}
+	})
+}
+
+func TestRemoteSourceUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL + "/catalog.json"
+	srv.Close()
+
+	s := newRemoteTestStore(t, url)
+	if ids := listIDs(t, s); len(ids) != 1 || ids[0] != "local-a" {
+		t.Fatalf("站点 = %v，远程站点目录不可达时应使用本地站点包", ids)
+	}
+	status, ok := s.RemoteStatus()
+	if !ok || status.Err == nil || !status.LastUpdate.IsZero() {
+		t.Fatalf("同步状态 = %+v，应记录错误且尚未成功获取", status)
+	}
+}
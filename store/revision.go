@@ -0,0 +1,124 @@
+package store
+
+import (
+	"ai-navigator/models"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// ErrRevisionNotFound 修订记录不存在
+var ErrRevisionNotFound = errors.New("修订记录不存在")
+
+// RevisionLog 记录站点修订历史的存储实现
+type RevisionLog interface {
+	// RecordRevision 追加一条修订记录，并返回分配了 ID 的记录
+	RecordRevision(rev models.Revision) (models.Revision, error)
+	// ListRevisions 按时间倒序返回指定站点的修订记录
+	ListRevisions(siteID string) ([]models.Revision, error)
+	// GetRevision 根据 ID 查找修订记录
+	GetRevision(id int64) (models.Revision, error)
+}
+
+func (s *JSONStore) RecordRevision(rev models.Revision) (models.Revision, error) {
+	s.revMu.Lock()
+	defer s.revMu.Unlock()
+
+	// 文件在上次写入后没有被其他程序改动时直接使用内存中的最后 ID，否则重新读取文件
+	if !s.revisionStamp.equal(statFile(s.revisionPath)) {
+		revisions, err := s.readRevisions()
+		if err != nil {
+			return rev, err
+		}
+		s.lastRevisionID = 0
+		if len(revisions) > 0 {
+			s.lastRevisionID = revisions[len(revisions)-1].ID
+		}
+	}
+	rev.ID = s.lastRevisionID + 1
+
+	line, err := json.Marshal(rev)
+	if err != nil {
+		return rev, fmt.Errorf("序列化修订记录失败: %w", err)
+	}
+
+	f, err := os.OpenFile(s.revisionPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return rev, fmt.Errorf("打开修订记录文件失败: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return rev, fmt.Errorf("写入修订记录失败: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return rev, err
+	}
+	s.lastRevisionID = rev.ID
+	s.revisionStamp = statFile(s.revisionPath)
+	return rev, nil
+}
+
+func (s *JSONStore) ListRevisions(siteID string) ([]models.Revision, error) {
+	s.revMu.Lock()
+	defer s.revMu.Unlock()
+
+	revisions, err := s.readRevisions()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []models.Revision
+	for _, rev := range revisions {
+		if rev.SiteID == siteID {
+			result = append(result, rev)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID > result[j].ID
+	})
+	return result, nil
+}
+
+func (s *JSONStore) GetRevision(id int64) (models.Revision, error) {
+	s.revMu.Lock()
+	defer s.revMu.Unlock()
+
+	revisions, err := s.readRevisions()
+	if err != nil {
+		return models.Revision{}, err
+	}
+	for _, rev := range revisions {
+		if rev.ID == id {
+			return rev, nil
+		}
+	}
+	return models.Revision{}, ErrRevisionNotFound
+}
+
+// readRevisions 读取全部修订记录，每行一条 JSON；末尾写了一半的行会被忽略
+func (s *JSONStore) readRevisions() ([]models.Revision, error) {
+	f, err := os.Open(s.revisionPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取修订记录失败: %w", err)
+	}
+	defer f.Close()
+
+	var revisions []models.Revision
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var rev models.Revision
+		if err := json.Unmarshal(scanner.Bytes(), &rev); err != nil {
+			continue
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, scanner.Err()
+}
@@ -0,0 +1,156 @@
+package store
+
+import (
+	"ai-navigator/models"
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+const categorySchema = `
+CREATE TABLE IF NOT EXISTS categories (
+	slug        TEXT PRIMARY KEY,
+	name        TEXT NOT NULL,
+	icon        TEXT NOT NULL DEFAULT '',
+	description TEXT NOT NULL DEFAULT '',
+	parent      TEXT NOT NULL DEFAULT '',
+	sort_order  INTEGER NOT NULL DEFAULT 0
+)`
+
+const categoryColumns = "slug, name, icon, description, parent, sort_order"
+
+// seedCategories 分类表为空时导入数据目录中的 categories.json
+func (s *SQLiteStore) seedCategories(source *JSONStore) error {
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM categories").Scan(&count); err != nil {
+		return fmt.Errorf("查询分类数量失败: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	categories, err := source.ListCategories()
+	if err != nil {
+		log.Printf("未导入分类数据: %v", err)
+		return nil
+	}
+	if len(categories) == 0 {
+		return nil
+	}
+
+	return s.withTx(func(tx *sql.Tx) error {
+		for _, category := range categories {
+			if err := insertCategory(tx, category); err != nil {
+				return err
+			}
+		}
+		log.Printf("已从 JSON 数据导入 %d 个分类到 SQLite", len(categories))
+		return nil
+	})
+}
+
+func (s *SQLiteStore) ListCategories() ([]models.Category, error) {
+	rows, err := s.db.Query("SELECT " + categoryColumns + " FROM categories")
+	if err != nil {
+		return nil, fmt.Errorf("查询分类失败: %w", err)
+	}
+	defer rows.Close()
+
+	categories, err := scanCategories(rows)
+	if err != nil {
+		return nil, err
+	}
+	// 数据库可能被其他程序直接修改，与 categories.json 一样读取时校验
+	if errs := validateCategories("categories", categories); len(errs) > 0 {
+		return nil, errs
+	}
+	sortCategories(categories)
+	return categories, nil
+}
+
+func (s *SQLiteStore) CreateCategory(category models.Category) error {
+	return s.mutateCategories(func(tx *sql.Tx, categories []models.Category) ([]models.Category, error) {
+		if indexOfCategory(categories, category.Slug) >= 0 {
+			return nil, ErrCategoryExists
+		}
+		return append(categories, category), insertCategory(tx, category)
+	})
+}
+
+func (s *SQLiteStore) UpdateCategory(category models.Category) error {
+	return s.mutateCategories(func(tx *sql.Tx, categories []models.Category) ([]models.Category, error) {
+		i := indexOfCategory(categories, category.Slug)
+		if i < 0 {
+			return nil, ErrCategoryNotFound
+		}
+		categories[i] = category
+		_, err := tx.Exec("UPDATE categories SET name = ?, icon = ?, description = ?, parent = ?, sort_order = ? WHERE slug = ?",
+			category.Name, category.Icon, category.Description, category.Parent, category.Order, category.Slug)
+		if err != nil {
+			return nil, fmt.Errorf("更新分类 %s 失败: %w", category.Slug, err)
+		}
+		return categories, nil
+	})
+}
+
+func (s *SQLiteStore) DeleteCategory(slug string) error {
+	return s.mutateCategories(func(tx *sql.Tx, categories []models.Category) ([]models.Category, error) {
+		i := indexOfCategory(categories, slug)
+		if i < 0 {
+			return nil, ErrCategoryNotFound
+		}
+		if hasChildCategory(categories, slug) {
+			return nil, ErrCategoryHasChildren
+		}
+		if _, err := tx.Exec("DELETE FROM categories WHERE slug = ?", slug); err != nil {
+			return nil, fmt.Errorf("删除分类 %s 失败: %w", slug, err)
+		}
+		return append(categories[:i], categories[i+1:]...), nil
+	})
+}
+
+// mutateCategories 在事务中读取全部分类并执行修改，修改后的分类校验失败时回滚
+func (s *SQLiteStore) mutateCategories(fn func(tx *sql.Tx, categories []models.Category) ([]models.Category, error)) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		rows, err := tx.Query("SELECT " + categoryColumns + " FROM categories")
+		if err != nil {
+			return fmt.Errorf("查询分类失败: %w", err)
+		}
+		categories, err := scanCategories(rows)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+
+		categories, err = fn(tx, categories)
+		if err != nil {
+			return err
+		}
+		if errs := validateCategories("categories", categories); len(errs) > 0 {
+			return errs
+		}
+		return nil
+	})
+}
+
+func scanCategories(rows *sql.Rows) ([]models.Category, error) {
+	var categories []models.Category
+	for rows.Next() {
+		var category models.Category
+		err := rows.Scan(&category.Slug, &category.Name, &category.Icon, &category.Description, &category.Parent, &category.Order)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+func insertCategory(tx *sql.Tx, category models.Category) error {
+	_, err := tx.Exec("INSERT INTO categories ("+categoryColumns+") VALUES (?, ?, ?, ?, ?, ?)",
+		category.Slug, category.Name, category.Icon, category.Description, category.Parent, category.Order)
+	if err != nil {
+		return fmt.Errorf("写入分类 %s 失败: %w", category.Slug, err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package store
+
+import (
+	"ai-navigator/models"
+	"encoding/json"
+	"fmt"
+)
+
+// marshalPricing 将定价序列化为 JSON 存入 pricing 列，没有定价时为空字符串
+func marshalPricing(pricing *models.Pricing) (string, error) {
+	if pricing == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(pricing)
+	if err != nil {
+		return "", fmt.Errorf("序列化定价失败: %w", err)
+	}
+	return string(data), nil
+}
+
+func unmarshalPricing(data string) (*models.Pricing, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var pricing models.Pricing
+	if err := json.Unmarshal([]byte(data), &pricing); err != nil {
+		return nil, err
+	}
+	return &pricing, nil
+}
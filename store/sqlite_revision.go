@@ -0,0 +1,100 @@
+package store
+
+import (
+	"ai-navigator/models"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+func (s *SQLiteStore) RecordRevision(rev models.Revision) (models.Revision, error) {
+	before, err := marshalSnapshot(rev.Before)
+	if err != nil {
+		return rev, err
+	}
+	after, err := marshalSnapshot(rev.After)
+	if err != nil {
+		return rev, err
+	}
+
+	err = s.ownWrite(func() error {
+		res, err := s.db.Exec("INSERT INTO revisions (site_id, action, actor, created_at, before, after) VALUES (?, ?, ?, ?, ?, ?)",
+			rev.SiteID, rev.Action, rev.Actor, rev.Time.Format(time.RFC3339Nano), before, after)
+		if err != nil {
+			return fmt.Errorf("写入修订记录失败: %w", err)
+		}
+		rev.ID, err = res.LastInsertId()
+		return err
+	})
+	return rev, err
+}
+
+func (s *SQLiteStore) ListRevisions(siteID string) ([]models.Revision, error) {
+	rows, err := s.db.Query("SELECT id, site_id, action, actor, created_at, before, after FROM revisions WHERE site_id = ? ORDER BY id DESC", siteID)
+	if err != nil {
+		return nil, fmt.Errorf("查询修订记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []models.Revision
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+func (s *SQLiteStore) GetRevision(id int64) (models.Revision, error) {
+	row := s.db.QueryRow("SELECT id, site_id, action, actor, created_at, before, after FROM revisions WHERE id = ?", id)
+	rev, err := scanRevision(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Revision{}, ErrRevisionNotFound
+	}
+	return rev, err
+}
+
+func scanRevision(row rowScanner) (models.Revision, error) {
+	var rev models.Revision
+	var createdAt string
+	var before, after sql.NullString
+	if err := row.Scan(&rev.ID, &rev.SiteID, &rev.Action, &rev.Actor, &createdAt, &before, &after); err != nil {
+		return models.Revision{}, err
+	}
+
+	rev.Time, _ = time.Parse(time.RFC3339Nano, createdAt)
+	var err error
+	if rev.Before, err = unmarshalSnapshot(before); err != nil {
+		return models.Revision{}, err
+	}
+	if rev.After, err = unmarshalSnapshot(after); err != nil {
+		return models.Revision{}, err
+	}
+	return rev, nil
+}
+
+func marshalSnapshot(site *models.Site) (sql.NullString, error) {
+	if site == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(site)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("序列化站点快照失败: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func unmarshalSnapshot(data sql.NullString) (*models.Site, error) {
+	if !data.Valid {
+		return nil, nil
+	}
+	var site models.Site
+	if err := json.Unmarshal([]byte(data.String), &site); err != nil {
+		return nil, fmt.Errorf("解析站点快照失败: %w", err)
+	}
+	return &site, nil
+}
@@ -0,0 +1,525 @@
+package store
+
+import (
+	"ai-navigator/config"
+	"ai-navigator/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS sites (
+	pos         INTEGER PRIMARY KEY AUTOINCREMENT,
+	id          TEXT NOT NULL UNIQUE,
+	name        TEXT NOT NULL,
+	url         TEXT NOT NULL DEFAULT '',
+	description TEXT NOT NULL DEFAULT '',
+	logo        TEXT NOT NULL DEFAULT '',
+	tags        TEXT NOT NULL DEFAULT '[]',
+	category    TEXT NOT NULL DEFAULT '',
+	rating      REAL NOT NULL DEFAULT 0,
+	visits      INTEGER NOT NULL DEFAULT 0,
+	featured    INTEGER NOT NULL DEFAULT 0,
+	created_at  TEXT NOT NULL DEFAULT '',
+	deleted     INTEGER NOT NULL DEFAULT 0,
+	deleted_at  TEXT NOT NULL DEFAULT '',
+	deleted_by  TEXT NOT NULL DEFAULT '',
+	pricing     TEXT NOT NULL DEFAULT ''
+)`
+
+const revisionSchema = `
+CREATE TABLE IF NOT EXISTS revisions (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	site_id    TEXT NOT NULL,
+	action     TEXT NOT NULL,
+	actor      TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL,
+	before     TEXT,
+	after      TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_revisions_site ON revisions (site_id)`
+
+const siteColumns = "id, name, url, description, logo, tags, category, rating, visits, featured, created_at, deleted, deleted_at, deleted_by, pricing"
+
+// sqlitePollInterval 检查数据库是否被其他连接修改的间隔
+const sqlitePollInterval = 2 * time.Second
+
+// SQLiteStore 基于 SQLite 的存储实现，所有写操作都在事务中完成
+type SQLiteStore struct {
+	db *sql.DB
+
+	// mu 保护监控状态，服务自身的写操作也在持有 mu 时进行，见 ownWrite
+	mu   sync.Mutex
+	stop chan struct{}
+	// watchConn 轮询 PRAGMA data_version 的专用连接，version 为已经处理过的版本
+	watchConn *sql.Conn
+	version   int64
+}
+
+// NewSQLiteStore 打开 path 指向的数据库，首次使用时从 cfg 数据目录下的 JSON 数据导入站点、分类和标签
+// 导入站点时按配置的站点包优先级合并，与 json 存储看到的数据相同
+func NewSQLiteStore(path string, cfg config.StorageConfig) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_journal_mode=WAL&_foreign_keys=on")
+	if err != nil {
+		return nil, fmt.Errorf("打开 SQLite 数据库失败: %w", err)
+	}
+	for _, schema := range []string{sqliteSchema, revisionSchema, categorySchema, tagSchema} {
+		if _, err := db.Exec(schema); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("初始化 SQLite 表结构失败: %w", err)
+		}
+	}
+
+	s := &SQLiteStore{db: db}
+	seed := readOnlySource(cfg)
+	if err := s.seed(seed); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := s.seedCategories(seed); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := s.seedTags(seed); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// seed 数据库为空时导入现有 JSON 数据，方便从 JSON 存储迁移
+func (s *SQLiteStore) seed(source *JSONStore) error {
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM sites").Scan(&count); err != nil {
+		return fmt.Errorf("查询站点数量失败: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	sites, err := source.List()
+	if err != nil {
+		log.Printf("未导入初始数据: %v", err)
+		return nil
+	}
+
+	return s.withTx(func(tx *sql.Tx) error {
+		for _, site := range sites {
+			if err := insertSite(tx, site); err != nil {
+				return err
+			}
+		}
+		log.Printf("已从 JSON 数据导入 %d 个站点到 SQLite", len(sites))
+		return nil
+	})
+}
+
+// openSQLiteReadOnly 以只读模式打开已有的数据库，不建表也不导入数据，数据库不存在时返回错误
+func openSQLiteReadOnly(path string) (*SQLiteStore, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("打开 SQLite 数据库失败: %w", err)
+	}
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("打开 SQLite 数据库失败: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) List() ([]models.Site, error) {
+	rows, err := s.db.Query("SELECT " + siteColumns + " FROM sites WHERE deleted = 0 ORDER BY pos")
+	if err != nil {
+		return nil, fmt.Errorf("查询站点失败: %w", err)
+	}
+	defer rows.Close()
+
+	var sites []models.Site
+	for rows.Next() {
+		site, err := scanSite(rows)
+		if err != nil {
+			return nil, err
+		}
+		sites = append(sites, site)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if errs := validateSites("sites", sites); len(errs) > 0 {
+		return nil, errs
+	}
+	return sites, nil
+}
+
+func (s *SQLiteStore) Get(id string) (models.Site, error) {
+	row := s.db.QueryRow("SELECT "+siteColumns+" FROM sites WHERE id = ? AND deleted = 0", id)
+	site, err := scanSite(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Site{}, ErrNotFound
+	}
+	return site, err
+}
+
+func (s *SQLiteStore) Create(site models.Site) (models.Site, error) {
+	if errs := ValidateSite(site); len(errs) > 0 {
+		return site, errs
+	}
+
+	err := s.withTx(func(tx *sql.Tx) error {
+		if site.ID == "" {
+			taken, err := takenIDs(tx)
+			if err != nil {
+				return err
+			}
+			site.ID = NewSiteID(site, taken)
+			return insertSite(tx, site)
+		}
+
+		deleted, err := lookupDeleted(tx, site.ID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return insertSite(tx, site)
+		case err != nil:
+			return err
+		case !deleted:
+			return ErrExists
+		}
+		// 指定的 ID 属于已删除站点时直接复用该行
+		return updateSite(tx, site.ID, site)
+	})
+	return site, err
+}
+
+func (s *SQLiteStore) Update(id string, site models.Site) error {
+	if errs := ValidateSite(site); len(errs) > 0 {
+		return errs
+	}
+
+	return s.withTx(func(tx *sql.Tx) error {
+		deleted, err := lookupDeleted(tx, id)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && deleted) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		site.ID = id
+		return updateSite(tx, id, site)
+	})
+}
+
+func (s *SQLiteStore) UpdateVersion(id, version string, site models.Site) (models.Site, error) {
+	if errs := ValidateSite(site); len(errs) > 0 {
+		return site, errs
+	}
+
+	var latest models.Site
+	err := s.withTx(func(tx *sql.Tx) error {
+		current, err := scanSite(tx.QueryRow("SELECT "+siteColumns+" FROM sites WHERE id = ? AND deleted = 0", id))
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if current.Version() != version {
+			latest = current
+			return ErrStale
+		}
+		site.ID = id
+		return updateSite(tx, id, site)
+	})
+	if errors.Is(err, ErrStale) {
+		return latest, err
+	}
+	site.ID = id
+	return site, err
+}
+
+func (s *SQLiteStore) SaveAll(batch []models.Site) ([]models.Site, error) {
+	for _, site := range batch {
+		if errs := ValidateSite(site); len(errs) > 0 {
+			return nil, errs
+		}
+	}
+
+	saved := make([]models.Site, len(batch))
+	err := s.withTx(func(tx *sql.Tx) error {
+		taken, err := takenIDs(tx)
+		if err != nil {
+			return err
+		}
+		for i, site := range batch {
+			if site.ID == "" {
+				site.ID = NewSiteID(site, taken)
+			}
+
+			_, err := lookupDeleted(tx, site.ID)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				taken[site.ID] = true
+				err = insertSite(tx, site)
+			case err == nil:
+				// 已删除的站点同样直接更新，相当于恢复
+				err = updateSite(tx, site.ID, site)
+			}
+			if err != nil {
+				return err
+			}
+			saved[i] = site
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+func (s *SQLiteStore) Delete(id, actor string) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		res, err := tx.Exec("UPDATE sites SET deleted = 1, deleted_at = ?, deleted_by = ? WHERE id = ? AND deleted = 0",
+			time.Now().Format(time.RFC3339), actor, id)
+		if err != nil {
+			return fmt.Errorf("删除站点失败: %w", err)
+		}
+		return requireAffected(res)
+	})
+}
+
+// Watch 轮询 PRAGMA data_version，其他进程修改数据库后回调 onChange
+// 服务自身的写入会同步更新已处理的版本，不会触发重新加载
+func (s *SQLiteStore) Watch(onChange func()) error {
+	conn, err := s.db.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+
+	version, err := dataVersion(conn)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	stop := make(chan struct{})
+	s.mu.Lock()
+	s.stop = stop
+	s.watchConn = conn
+	s.version = version
+	s.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(sqlitePollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				changed, err := s.pollVersion()
+				if err != nil {
+					log.Printf("监控错误: %v", err)
+					continue
+				}
+				if changed {
+					log.Println("检测到数据库变更，重新加载数据")
+					onChange()
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// pollVersion 检查数据库在上次检查之后是否被其他进程修改
+func (s *SQLiteStore) pollVersion() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.watchConn == nil {
+		return false, nil
+	}
+	current, err := dataVersion(s.watchConn)
+	if err != nil || current == s.version {
+		return false, err
+	}
+	s.version = current
+	return true, nil
+}
+
+// ownWrite 执行服务自身的写操作，写入前后各读取一次 data_version：
+// 写入前的版本已经处理过（期间没有其他进程的修改）时，把写入后的版本记为已处理，监控不会因此重新加载
+func (s *SQLiteStore) ownWrite(fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.watchConn == nil {
+		return fn()
+	}
+	before, err := dataVersion(s.watchConn)
+	if err != nil {
+		return fn()
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	if before == s.version {
+		if after, err := dataVersion(s.watchConn); err == nil {
+			s.version = after
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Close() error {
+	s.mu.Lock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+	if s.watchConn != nil {
+		s.watchConn.Close()
+		s.watchConn = nil
+	}
+	s.mu.Unlock()
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) withTx(fn func(tx *sql.Tx) error) error {
+	return s.ownWrite(func() error {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return fmt.Errorf("开启事务失败: %w", err)
+		}
+		if err := fn(tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// lookupDeleted 返回站点的删除标记，站点不存在时返回 sql.ErrNoRows
+func lookupDeleted(tx *sql.Tx, id string) (bool, error) {
+	var deleted bool
+	err := tx.QueryRow("SELECT deleted FROM sites WHERE id = ?", id).Scan(&deleted)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("查询站点失败: %w", err)
+	}
+	return deleted, err
+}
+
+// takenIDs 返回数据库中已使用的全部 ID，包括已删除站点
+func takenIDs(tx *sql.Tx) (map[string]bool, error) {
+	rows, err := tx.Query("SELECT id FROM sites")
+	if err != nil {
+		return nil, fmt.Errorf("查询站点 ID 失败: %w", err)
+	}
+	defer rows.Close()
+
+	taken := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		taken[id] = true
+	}
+	return taken, rows.Err()
+}
+
+func dataVersion(conn *sql.Conn) (int64, error) {
+	var version int64
+	err := conn.QueryRowContext(context.Background(), "PRAGMA data_version").Scan(&version)
+	return version, err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSite(row rowScanner) (models.Site, error) {
+	var site models.Site
+	var tags, pricing string
+	err := row.Scan(&site.ID, &site.Name, &site.URL, &site.Description, &site.Logo, &tags,
+		&site.Category, &site.Rating, &site.Visits, &site.Featured, &site.CreatedAt, &site.Deleted, &site.DeletedAt, &site.DeletedBy, &pricing)
+	if err != nil {
+		return models.Site{}, err
+	}
+	if err := json.Unmarshal([]byte(tags), &site.Tags); err != nil {
+		return models.Site{}, fmt.Errorf("解析站点 %s 的标签失败: %w", site.Name, err)
+	}
+	if site.Pricing, err = unmarshalPricing(pricing); err != nil {
+		return models.Site{}, fmt.Errorf("解析站点 %s 的定价失败: %w", site.Name, err)
+	}
+	return site, nil
+}
+
+func insertSite(tx *sql.Tx, site models.Site) error {
+	tags, err := marshalTags(site.Tags)
+	if err != nil {
+		return err
+	}
+	pricing, err := marshalPricing(site.Pricing)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("INSERT INTO sites ("+siteColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		site.ID, site.Name, site.URL, site.Description, site.Logo, tags,
+		site.Category, site.Rating, site.Visits, site.Featured, site.CreatedAt, site.Deleted, site.DeletedAt, site.DeletedBy, pricing)
+	if err != nil {
+		return fmt.Errorf("写入站点 %s 失败: %w", site.Name, err)
+	}
+	return nil
+}
+
+func updateSite(tx *sql.Tx, id string, site models.Site) error {
+	tags, err := marshalTags(site.Tags)
+	if err != nil {
+		return err
+	}
+	pricing, err := marshalPricing(site.Pricing)
+	if err != nil {
+		return err
+	}
+	res, err := tx.Exec(`UPDATE sites SET name = ?, url = ?, description = ?, logo = ?, tags = ?,
+		category = ?, rating = ?, visits = ?, featured = ?, created_at = ?, deleted = ?, deleted_at = ?, deleted_by = ?, pricing = ? WHERE id = ?`,
+		site.Name, site.URL, site.Description, site.Logo, tags,
+		site.Category, site.Rating, site.Visits, site.Featured, site.CreatedAt, site.Deleted, site.DeletedAt, site.DeletedBy, pricing, id)
+	if err != nil {
+		return fmt.Errorf("更新站点 %s 失败: %w", id, err)
+	}
+	return requireAffected(res)
+}
+
+func marshalTags(tags []string) (string, error) {
+	if tags == nil {
+		tags = []string{}
+	}
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return "", fmt.Errorf("序列化标签失败: %w", err)
+	}
+	return string(data), nil
+}
+
+func requireAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
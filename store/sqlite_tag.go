@@ -0,0 +1,142 @@
+package store
+
+import (
+	"ai-navigator/models"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+const tagSchema = `
+CREATE TABLE IF NOT EXISTS tags (
+	name        TEXT PRIMARY KEY,
+	aliases     TEXT NOT NULL DEFAULT '[]',
+	color       TEXT NOT NULL DEFAULT '',
+	description TEXT NOT NULL DEFAULT ''
+)`
+
+// seedTags 标签表为空时导入数据目录中的 tags.json
+func (s *SQLiteStore) seedTags(source *JSONStore) error {
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM tags").Scan(&count); err != nil {
+		return fmt.Errorf("查询标签数量失败: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	tags, err := source.ListTags()
+	if err != nil {
+		log.Printf("未导入标签数据: %v", err)
+		return nil
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	return s.withTx(func(tx *sql.Tx) error {
+		if err := writeTags(tx, tags); err != nil {
+			return err
+		}
+		log.Printf("已从 JSON 数据导入 %d 个标签到 SQLite", len(tags))
+		return nil
+	})
+}
+
+func (s *SQLiteStore) ListTags() ([]models.Tag, error) {
+	rows, err := s.db.Query("SELECT name, aliases, color, description FROM tags")
+	if err != nil {
+		return nil, fmt.Errorf("查询标签失败: %w", err)
+	}
+	defer rows.Close()
+
+	tags, err := scanTags(rows)
+	if err != nil {
+		return nil, err
+	}
+	sortTags(tags)
+	return tags, nil
+}
+
+func (s *SQLiteStore) CreateTag(tag models.Tag) error {
+	return s.mutateTags(func(tags []models.Tag) ([]models.Tag, error) {
+		return createTag(tags, tag)
+	})
+}
+
+func (s *SQLiteStore) UpdateTag(name string, tag models.Tag) error {
+	return s.mutateTags(func(tags []models.Tag) ([]models.Tag, error) {
+		return updateTag(tags, name, tag)
+	})
+}
+
+func (s *SQLiteStore) DeleteTag(name string) error {
+	return s.mutateTags(func(tags []models.Tag) ([]models.Tag, error) {
+		return deleteTag(tags, name)
+	})
+}
+
+func (s *SQLiteStore) MergeTags(source, target string) error {
+	return s.mutateTags(func(tags []models.Tag) ([]models.Tag, error) {
+		return mergeTags(tags, source, target)
+	})
+}
+
+// mutateTags 在事务中读取注册表并执行修改，校验通过后整体写回，标签数量很少，不逐行比较
+func (s *SQLiteStore) mutateTags(fn func(tags []models.Tag) ([]models.Tag, error)) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		rows, err := tx.Query("SELECT name, aliases, color, description FROM tags")
+		if err != nil {
+			return fmt.Errorf("查询标签失败: %w", err)
+		}
+		tags, err := scanTags(rows)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+
+		tags, err = fn(tags)
+		if err != nil {
+			return err
+		}
+		if errs := validateTags("tags", tags); len(errs) > 0 {
+			return errs
+		}
+		if _, err := tx.Exec("DELETE FROM tags"); err != nil {
+			return fmt.Errorf("更新标签失败: %w", err)
+		}
+		return writeTags(tx, tags)
+	})
+}
+
+func scanTags(rows *sql.Rows) ([]models.Tag, error) {
+	var tags []models.Tag
+	for rows.Next() {
+		var tag models.Tag
+		var aliases string
+		if err := rows.Scan(&tag.Name, &aliases, &tag.Color, &tag.Description); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(aliases), &tag.Aliases); err != nil {
+			return nil, fmt.Errorf("解析标签 %s 的别名失败: %w", tag.Name, err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+func writeTags(tx *sql.Tx, tags []models.Tag) error {
+	for _, tag := range tags {
+		aliases, err := marshalTags(tag.Aliases)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("INSERT INTO tags (name, aliases, color, description) VALUES (?, ?, ?, ?)",
+			tag.Name, aliases, tag.Color, tag.Description)
+		if err != nil {
+			return fmt.Errorf("写入标签 %s 失败: %w", tag.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"ai-navigator/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// trashCondition 回收站中的站点：已删除且没有被彻底删除
+const trashCondition = "deleted = 1 AND (name != '' OR url != '')"
+
+func (s *SQLiteStore) ListDeleted() ([]models.Site, error) {
+	rows, err := s.db.Query("SELECT " + siteColumns + " FROM sites WHERE " + trashCondition + " ORDER BY pos")
+	if err != nil {
+		return nil, fmt.Errorf("查询回收站失败: %w", err)
+	}
+	defer rows.Close()
+
+	var deleted []models.Site
+	for rows.Next() {
+		site, err := scanSite(rows)
+		if err != nil {
+			return nil, err
+		}
+		deleted = append(deleted, site)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sortByDeletedAt(deleted)
+	return deleted, nil
+}
+
+func (s *SQLiteStore) RestoreDeleted(id string) (models.Site, error) {
+	var restored models.Site
+	err := s.withTx(func(tx *sql.Tx) error {
+		site, err := scanSite(tx.QueryRow("SELECT "+siteColumns+" FROM sites WHERE id = ? AND "+trashCondition, id))
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		site.Deleted, site.DeletedAt, site.DeletedBy = false, "", ""
+		if errs := ValidateSite(site); len(errs) > 0 {
+			return errs
+		}
+		restored = site
+		return updateSite(tx, id, site)
+	})
+	return restored, err
+}
+
+func (s *SQLiteStore) Purge(id string) (models.Site, error) {
+	var purged models.Site
+	err := s.withTx(func(tx *sql.Tx) error {
+		site, err := scanSite(tx.QueryRow("SELECT "+siteColumns+" FROM sites WHERE id = ? AND "+trashCondition, id))
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		purged = site
+		return updateSite(tx, id, purgedSite(id))
+	})
+	return purged, err
+}
+
+func (s *SQLiteStore) PurgeDeletedBefore(t time.Time) ([]models.Site, error) {
+	var purged []models.Site
+	err := s.withTx(func(tx *sql.Tx) error {
+		rows, err := tx.Query("SELECT " + siteColumns + " FROM sites WHERE " + trashCondition)
+		if err != nil {
+			return fmt.Errorf("查询回收站失败: %w", err)
+		}
+		var expired []models.Site
+		for rows.Next() {
+			site, err := scanSite(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			if deletedBefore(site, t) {
+				expired = append(expired, site)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, site := range expired {
+			if err := updateSite(tx, site.ID, purgedSite(site.ID)); err != nil {
+				return err
+			}
+		}
+		purged = expired
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return purged, nil
+}
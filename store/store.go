@@ -0,0 +1,83 @@
+package store
+
+import (
+	"ai-navigator/config"
+	"ai-navigator/models"
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+var (
+	// ErrNotFound 站点不存在
+	ErrNotFound = errors.New("站点不存在")
+	// ErrExists 同名站点已存在
+	ErrExists = errors.New("站点已存在")
+	// ErrStale 站点在读取之后已被修改，提交的内容基于过期的版本
+	ErrStale = errors.New("站点已被修改")
+)
+
+// SiteStore 站点数据存储接口，首页、搜索和后台处理器都只依赖该接口
+type SiteStore interface {
+	// List 返回所有未删除的站点
+	List() ([]models.Site, error)
+	// Get 根据站点标识查找站点
+	Get(id string) (models.Site, error)
+	// Create 新增站点，未指定 ID 时自动生成，返回保存后的站点
+	Create(site models.Site) (models.Site, error)
+	// Update 更新指定站点
+	Update(id string, site models.Site) error
+	// Delete 删除指定站点，站点移入回收站并记录删除时间和操作人
+	Delete(id, actor string) error
+	// SaveAll 在一次写入中批量保存站点：ID 对应已有站点时更新，否则新建（未指定 ID 时自动生成）
+	// 任一站点校验失败时不写入任何数据，返回保存后的站点
+	SaveAll(sites []models.Site) ([]models.Site, error)
+	// Watch 在底层数据被外部修改时回调 onChange，直到 Close 被调用
+	Watch(onChange func()) error
+	// Close 释放存储占用的资源
+	Close() error
+}
+
+// VersionedUpdater 支持乐观并发控制的存储实现，版本检查与写入在同一次加锁或事务中完成
+type VersionedUpdater interface {
+	// UpdateVersion 仅当站点当前的版本（models.Site.Version）为 version 时更新，返回保存后的站点；
+	// 版本不一致时不写入，返回 ErrStale 和站点当前的内容
+	UpdateVersion(id, version string, site models.Site) (models.Site, error)
+}
+
+// Open 根据配置创建对应的存储后端
+func Open(cfg config.StorageConfig) (SiteStore, error) {
+	switch cfg.Driver {
+	case "", "json":
+		return NewJSONStore(cfg), nil
+	case "sqlite":
+		return NewSQLiteStore(sqlitePath(cfg), cfg)
+	default:
+		return nil, fmt.Errorf("未知的存储类型: %s", cfg.Driver)
+	}
+}
+
+// OpenReadOnly 以只读方式打开配置的存储，供命令行导出使用，不会修改任何数据
+// json 存储不拉取远程站点目录、不启用 git 提交，也不为缺少 ID 的站点改写站点包；sqlite 存储不建表也不导入数据
+func OpenReadOnly(cfg config.StorageConfig) (SiteStore, error) {
+	switch cfg.Driver {
+	case "", "json":
+		return readOnlySource(cfg), nil
+	case "sqlite":
+		return openSQLiteReadOnly(sqlitePath(cfg))
+	default:
+		return nil, fmt.Errorf("未知的存储类型: %s", cfg.Driver)
+	}
+}
+
+// sqlitePath 返回 SQLite 数据库的路径，未配置时为数据目录下的 sites.db
+func sqlitePath(cfg config.StorageConfig) string {
+	if cfg.SQLitePath != "" {
+		return cfg.SQLitePath
+	}
+	dataDir := cfg.DataDir
+	if dataDir == "" {
+		dataDir = "./data"
+	}
+	return filepath.Join(dataDir, "sites.db")
+}
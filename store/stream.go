@@ -0,0 +1,86 @@
+package store
+
+import (
+	"ai-navigator/models"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// decodeSitesJSONFile 逐条解析 JSON 站点包中的站点，不需要先把整个文件读入内存，
+// 大型社区站点目录加载时只占用解析结果的内存。错误与 decodeSitesJSON 一样转换为带行号的校验错误
+func decodeSitesJSONFile(file, path string) ([]models.Site, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 文件失败: %w", file, err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, jsonStreamError(file, f, err, 0)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return nil, ValidationErrors{{File: file, Index: -1, Reason: "JSON 格式错误: 文件内容应为站点数组"}}
+	}
+
+	var sites []models.Site
+	for dec.More() {
+		start := dec.InputOffset()
+		var site models.Site
+		if err := dec.Decode(&site); err != nil {
+			return nil, jsonStreamError(file, f, err, start)
+		}
+		sites = append(sites, site)
+	}
+	// 读取结尾的 ]，之后不能再有其它内容
+	if _, err := dec.Token(); err != nil {
+		return nil, jsonStreamError(file, f, err, 0)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		line := lineAt(f, dec.InputOffset())
+		return nil, ValidationErrors{{File: file, Index: -1, Reason: fmt.Sprintf("第 %d 行 JSON 语法错误: 站点数组之后还有多余的内容", line)}}
+	}
+	return sites, nil
+}
+
+// jsonStreamError 将流式解析的错误转换为校验错误，start 为出错的站点在文件中的起始位置
+// 类型错误的位置相对于站点的起始位置，语法错误的位置相对于文件开头
+func jsonStreamError(file string, f io.ReadSeeker, err error, start int64) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		line := lineAt(f, syntaxErr.Offset)
+		return ValidationErrors{{File: file, Index: -1, Reason: fmt.Sprintf("第 %d 行 JSON 语法错误: %v", line, err)}}
+	case errors.As(err, &typeErr):
+		line := lineAt(f, start+typeErr.Offset)
+		return ValidationErrors{{File: file, Index: -1, Field: typeErr.Field,
+			Reason: fmt.Sprintf("第 %d 行字段 %s 类型错误，应为 %s", line, typeErr.Field, typeErr.Type)}}
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ValidationErrors{{File: file, Index: -1, Reason: "JSON 语法错误: 文件内容不完整"}}
+	default:
+		return fmt.Errorf("读取 %s 文件失败: %w", file, err)
+	}
+}
+
+// lineAt 返回文件中 offset 位置所在的行号，从 1 开始，只在出错时使用
+func lineAt(f io.ReadSeeker, offset int64) int {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0
+	}
+	line := 1
+	buf := make([]byte, 32*1024)
+	r := io.LimitReader(f, offset)
+	for {
+		n, err := r.Read(buf)
+		line += bytes.Count(buf[:n], []byte("\n"))
+		if err != nil {
+			return line
+		}
+	}
+}
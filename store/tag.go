@@ -0,0 +1,249 @@
+package store
+
+import (
+	"ai-navigator/models"
+	"ai-navigator/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// tagsFile 标签注册表文件，不作为站点包加载
+const tagsFile = "tags.json"
+
+var (
+	// ErrTagNotFound 标签不存在
+	ErrTagNotFound = errors.New("标签不存在")
+	// ErrTagExists 名称或别名相同的标签已存在
+	ErrTagExists = errors.New("标签已存在")
+)
+
+// tagColorPattern 标签颜色的格式
+var tagColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// TagStore 支持标签注册表的存储实现，只维护注册表本身，站点中的标签由调用方批量修改
+type TagStore interface {
+	// ListTags 按名称返回注册表中的全部标签
+	ListTags() ([]models.Tag, error)
+	// CreateTag 新增标签，名称或别名与已有标签冲突时返回 ErrTagExists
+	CreateTag(tag models.Tag) error
+	// UpdateTag 更新名称为 name 的标签，tag.Name 不同时即为重命名
+	UpdateTag(name string, tag models.Tag) error
+	// DeleteTag 从注册表中删除标签，站点中的标签保持不变
+	DeleteTag(name string) error
+	// MergeTags 将标签 source 合并到 target：source 的名称和别名成为 target 的别名，source 从注册表中删除
+	MergeTags(source, target string) error
+}
+
+// cleanTag 整理标签的名称和别名，去掉空白别名和与名称或其它别名重复的别名
+func cleanTag(tag models.Tag) models.Tag {
+	tag.Name = utils.CleanTag(tag.Name)
+	tag.Color = strings.TrimSpace(tag.Color)
+	tag.Description = strings.TrimSpace(tag.Description)
+
+	seen := map[string]bool{utils.TagKey(tag.Name): true}
+	var aliases []string
+	for _, alias := range tag.Aliases {
+		alias = utils.CleanTag(alias)
+		key := utils.TagKey(alias)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		aliases = append(aliases, alias)
+	}
+	tag.Aliases = aliases
+	return tag
+}
+
+// validateTags 校验注册表：名称不能为空，全部标签的名称和别名按 utils.TagKey 比较不能重复，颜色为 #rrggbb
+func validateTags(file string, tags []models.Tag) ValidationErrors {
+	var errs ValidationErrors
+	add := func(i int, tag models.Tag, field, reason string) {
+		errs = append(errs, ValidationError{File: file, Index: i, SiteID: tag.Name, Field: field, Reason: reason})
+	}
+
+	owner := make(map[string]int)
+	claim := func(i int, tag models.Tag, field, value string) {
+		key := utils.TagKey(value)
+		if first, ok := owner[key]; ok && first != i {
+			add(i, tag, field, fmt.Sprintf("%s 与标签 %s 重复", value, tags[first].Name))
+			return
+		}
+		owner[key] = i
+	}
+
+	for i, tag := range tags {
+		if utils.TagKey(tag.Name) == "" {
+			add(i, tag, "name", "标签名称不能为空")
+		} else if strings.ContainsAny(tag.Name, ",，") {
+			add(i, tag, "name", "标签名称不能包含逗号")
+		} else {
+			claim(i, tag, "name", tag.Name)
+		}
+		for _, alias := range tag.Aliases {
+			if strings.ContainsAny(alias, ",，") {
+				add(i, tag, "aliases", "别名不能包含逗号")
+				continue
+			}
+			claim(i, tag, "aliases", alias)
+		}
+		if tag.Color != "" && !tagColorPattern.MatchString(tag.Color) {
+			add(i, tag, "color", "颜色必须是 #rrggbb 格式")
+		}
+	}
+	return errs
+}
+
+func sortTags(tags []models.Tag) {
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+}
+
+// indexOfTag 返回名称为 name 的标签的下标，按 utils.TagKey 比较，不存在时返回 -1
+func indexOfTag(tags []models.Tag, name string) int {
+	key := utils.TagKey(name)
+	for i, tag := range tags {
+		if utils.TagKey(tag.Name) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// createTag、updateTag、deleteTag、mergeTags 在注册表上执行对应的修改，JSON 和 SQLite 存储共用
+func createTag(tags []models.Tag, tag models.Tag) ([]models.Tag, error) {
+	if indexOfTag(tags, tag.Name) >= 0 {
+		return nil, ErrTagExists
+	}
+	return append(tags, cleanTag(tag)), nil
+}
+
+func updateTag(tags []models.Tag, name string, tag models.Tag) ([]models.Tag, error) {
+	i := indexOfTag(tags, name)
+	if i < 0 {
+		return nil, ErrTagNotFound
+	}
+	if j := indexOfTag(tags, tag.Name); j >= 0 && j != i {
+		return nil, ErrTagExists
+	}
+	tags[i] = cleanTag(tag)
+	return tags, nil
+}
+
+func deleteTag(tags []models.Tag, name string) ([]models.Tag, error) {
+	i := indexOfTag(tags, name)
+	if i < 0 {
+		return nil, ErrTagNotFound
+	}
+	return append(tags[:i], tags[i+1:]...), nil
+}
+
+func mergeTags(tags []models.Tag, source, target string) ([]models.Tag, error) {
+	i, j := indexOfTag(tags, source), indexOfTag(tags, target)
+	if i < 0 || j < 0 {
+		return nil, ErrTagNotFound
+	}
+	if i == j {
+		return tags, nil
+	}
+	merged := tags[j]
+	merged.Aliases = append(append(append([]string(nil), merged.Aliases...), tags[i].Name), tags[i].Aliases...)
+	if merged.Color == "" {
+		merged.Color = tags[i].Color
+	}
+	if merged.Description == "" {
+		merged.Description = tags[i].Description
+	}
+	tags[j] = cleanTag(merged)
+	return append(tags[:i], tags[i+1:]...), nil
+}
+
+func (s *JSONStore) ListTags() ([]models.Tag, error) {
+	s.tagMu.Lock()
+	defer s.tagMu.Unlock()
+
+	tags, err := s.readTags()
+	if err != nil {
+		return nil, err
+	}
+	sortTags(tags)
+	return tags, nil
+}
+
+func (s *JSONStore) CreateTag(tag models.Tag) error {
+	return s.mutateTags(func(tags []models.Tag) ([]models.Tag, error) {
+		return createTag(tags, tag)
+	})
+}
+
+func (s *JSONStore) UpdateTag(name string, tag models.Tag) error {
+	return s.mutateTags(func(tags []models.Tag) ([]models.Tag, error) {
+		return updateTag(tags, name, tag)
+	})
+}
+
+func (s *JSONStore) DeleteTag(name string) error {
+	return s.mutateTags(func(tags []models.Tag) ([]models.Tag, error) {
+		return deleteTag(tags, name)
+	})
+}
+
+func (s *JSONStore) MergeTags(source, target string) error {
+	return s.mutateTags(func(tags []models.Tag) ([]models.Tag, error) {
+		return mergeTags(tags, source, target)
+	})
+}
+
+// mutateTags 读取注册表，修改并校验后写回 tags.json
+func (s *JSONStore) mutateTags(fn func(tags []models.Tag) ([]models.Tag, error)) error {
+	s.tagMu.Lock()
+	defer s.tagMu.Unlock()
+
+	tags, err := s.readTags()
+	if err != nil {
+		return err
+	}
+	tags, err = fn(tags)
+	if err != nil {
+		return err
+	}
+	if errs := validateTags(tagsFile, tags); len(errs) > 0 {
+		return errs
+	}
+	sortTags(tags)
+
+	data, err := json.MarshalIndent(tags, "", "    ")
+	if err != nil {
+		return fmt.Errorf("序列化JSON失败: %w", err)
+	}
+	if err := s.writeFile(s.tagsPath, data); err != nil {
+		return fmt.Errorf("写入 %s 文件失败: %w", tagsFile, err)
+	}
+	return nil
+}
+
+// readTags 读取并校验 tags.json，文件不存在时注册表为空，调用方需持有 s.tagMu
+func (s *JSONStore) readTags() ([]models.Tag, error) {
+	data, err := os.ReadFile(s.tagsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 文件失败: %w", tagsFile, err)
+	}
+
+	var tags []models.Tag
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return nil, ValidationErrors{{File: tagsFile, Index: -1, Reason: fmt.Sprintf("JSON 格式错误: %v", err)}}
+	}
+	if errs := validateTags(tagsFile, tags); len(errs) > 0 {
+		return nil, errs
+	}
+	return tags, nil
+}
@@ -0,0 +1,133 @@
+package store
+
+import (
+	"ai-navigator/models"
+	"errors"
+	"sort"
+	"time"
+)
+
+// Trash 支持回收站的存储实现：删除的站点保留完整数据，可以恢复或彻底删除
+// 彻底删除的站点只保留 ID 和删除标记，避免站点包中的同 ID 站点重新出现，也避免新站点复用该 ID
+type Trash interface {
+	// ListDeleted 返回回收站中的站点，最近删除的在前
+	ListDeleted() ([]models.Site, error)
+	// RestoreDeleted 将回收站中的站点恢复为删除前的状态，返回恢复后的站点
+	RestoreDeleted(id string) (models.Site, error)
+	// Purge 彻底删除回收站中的站点，返回被删除的站点
+	Purge(id string) (models.Site, error)
+	// PurgeDeletedBefore 彻底删除在 t 之前移入回收站的全部站点，返回被删除的站点
+	PurgeDeletedBefore(t time.Time) ([]models.Site, error)
+}
+
+// errNoChange 没有需要写入的修改，调用方据此跳过写入
+var errNoChange = errors.New("没有需要写入的修改")
+
+// markDeleted 将站点移入回收站
+func markDeleted(site *models.Site, actor string, now time.Time) {
+	site.Deleted = true
+	site.DeletedAt = now.Format(time.RFC3339)
+	site.DeletedBy = actor
+}
+
+// purgedSite 彻底删除后留下的记录，只有 ID 和删除标记
+func purgedSite(id string) models.Site {
+	return models.Site{ID: id, Deleted: true}
+}
+
+// inTrash 站点已删除但还没有被彻底删除
+func inTrash(site models.Site) bool {
+	return site.Deleted && (site.Name != "" || site.URL != "")
+}
+
+// deletedBefore 站点的删除时间早于 t，没有记录删除时间的旧数据不会被自动清理
+func deletedBefore(site models.Site, t time.Time) bool {
+	deletedAt, err := time.Parse(time.RFC3339, site.DeletedAt)
+	return err == nil && deletedAt.Before(t)
+}
+
+// sortByDeletedAt 按删除时间倒序排列，没有删除时间的排在最后
+func sortByDeletedAt(sites []models.Site) {
+	sort.SliceStable(sites, func(i, j int) bool {
+		a, errA := time.Parse(time.RFC3339, sites[i].DeletedAt)
+		b, errB := time.Parse(time.RFC3339, sites[j].DeletedAt)
+		if errA != nil || errB != nil {
+			return errA == nil && errB != nil
+		}
+		return a.After(b)
+	})
+}
+
+func (s *JSONStore) ListDeleted() ([]models.Site, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, customSites, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []models.Site
+	for _, site := range customSites {
+		if inTrash(site) {
+			deleted = append(deleted, site)
+		}
+	}
+	sortByDeletedAt(deleted)
+	return deleted, nil
+}
+
+func (s *JSONStore) RestoreDeleted(id string) (models.Site, error) {
+	var restored models.Site
+	err := s.mutateCustom(func(_, customSites []models.Site) ([]models.Site, error) {
+		i := indexOf(customSites, id)
+		if i < 0 || !inTrash(customSites[i]) {
+			return nil, ErrNotFound
+		}
+		restored = customSites[i]
+		restored.Deleted, restored.DeletedAt, restored.DeletedBy = false, "", ""
+		customSites[i] = restored
+		return customSites, nil
+	})
+	return restored, err
+}
+
+func (s *JSONStore) Purge(id string) (models.Site, error) {
+	var purged models.Site
+	err := s.mutateCustom(func(_, customSites []models.Site) ([]models.Site, error) {
+		i := indexOf(customSites, id)
+		if i < 0 || !inTrash(customSites[i]) {
+			return nil, ErrNotFound
+		}
+		purged = customSites[i]
+		customSites[i] = purgedSite(id)
+		return customSites, nil
+	})
+	return purged, err
+}
+
+func (s *JSONStore) PurgeDeletedBefore(t time.Time) ([]models.Site, error) {
+	var purged []models.Site
+	err := s.mutateCustom(func(_, customSites []models.Site) ([]models.Site, error) {
+		for i, site := range customSites {
+			if inTrash(site) && deletedBefore(site, t) {
+				purged = append(purged, site)
+				customSites[i] = purgedSite(site.ID)
+			}
+		}
+		if len(purged) == 0 {
+			return nil, errNoChange
+		}
+		return customSites, nil
+	})
+	if errors.Is(err, errNoChange) {
+		return nil, nil
+	}
+	return purged, err
+}
+
+// mutateCustom 直接修改 custom.json 中的记录（包括已删除的站点）并写回，与站点包相同的站点会被去掉
+// 记录是应用补丁后的完整站点，写回时重新计算补丁
+func (s *JSONStore) mutateCustom(fn func(upstream, customSites []models.Site) ([]models.Site, error)) error {
+	return s.submit(fn)
+}
@@ -0,0 +1,199 @@
+package store
+
+import (
+	"ai-navigator/models"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ValidationError 数据文件中某个站点字段的校验错误
+type ValidationError struct {
+	File   string
+	Index  int // 站点在文件中的下标，-1 表示整个文件
+	SiteID string
+	Field  string
+	Reason string
+}
+
+func (e ValidationError) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("%s: %s", e.File, e.Reason)
+	}
+	return fmt.Sprintf("%s[%d].%s: %s", e.File, e.Index, e.Field, e.Reason)
+}
+
+// ValidationErrors 一次加载中发现的全部校验错误
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%s（共 %d 个错误）", e[0].Error(), len(e))
+}
+
+// fieldRule 单个字段的校验规则，返回空字符串表示通过
+type fieldRule struct {
+	Field string
+	Check func(site models.Site) string
+}
+
+// siteSchema 站点数据的字段约束，加载和保存时都按此校验
+var siteSchema = []fieldRule{
+	{"name", func(site models.Site) string {
+		if strings.TrimSpace(site.Name) == "" {
+			return "名称不能为空"
+		}
+		return ""
+	}},
+	{"url", func(site models.Site) string {
+		if site.URL == "" {
+			return "网址不能为空"
+		}
+		if !isHTTPURL(site.URL) {
+			return "网址必须是 http 或 https 开头的完整地址"
+		}
+		return ""
+	}},
+	{"logo", func(site models.Site) string {
+		if site.Logo == "" || strings.HasPrefix(site.Logo, "/") || isHTTPURL(site.Logo) {
+			return ""
+		}
+		return "Logo 必须是以 / 开头的站内路径或完整网址"
+	}},
+	{"tags", func(site models.Site) string {
+		for _, tag := range site.Tags {
+			if strings.TrimSpace(tag) == "" {
+				return "标签不能为空字符串"
+			}
+		}
+		return ""
+	}},
+	{"rating", func(site models.Site) string {
+		if site.Rating < 0 || site.Rating > 5 {
+			return fmt.Sprintf("评分 %g 超出 0-5 的范围", site.Rating)
+		}
+		return ""
+	}},
+	{"visits", func(site models.Site) string {
+		if site.Visits < 0 {
+			return "访问量不能为负数"
+		}
+		return ""
+	}},
+	{"pricing", func(site models.Site) string {
+		if site.Pricing == nil {
+			return ""
+		}
+		return checkPricing(*site.Pricing)
+	}},
+}
+
+// currencyPattern 货币代码的格式（ISO 4217）
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// datePattern 定价中日期的格式
+var datePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// checkPricing 校验定价：模式和计费周期必须是已知的取值，套餐要有名称，价格不能为负数，有价格时要填写货币
+func checkPricing(pricing models.Pricing) string {
+	if !slices.Contains(models.PricingModels, pricing.Model) {
+		return fmt.Sprintf("定价模式 %q 无效，可选 %s", pricing.Model, strings.Join(models.PricingModels, "、"))
+	}
+	for _, plan := range pricing.Plans {
+		if strings.TrimSpace(plan.Name) == "" {
+			return "套餐名称不能为空"
+		}
+		if plan.Price < 0 {
+			return fmt.Sprintf("套餐 %s 的价格不能为负数", plan.Name)
+		}
+		if plan.Price > 0 && plan.Currency == "" {
+			return fmt.Sprintf("套餐 %s 需要填写货币", plan.Name)
+		}
+		if plan.Currency != "" && !currencyPattern.MatchString(plan.Currency) {
+			return fmt.Sprintf("套餐 %s 的货币 %q 必须是三个大写字母，如 USD、CNY", plan.Name, plan.Currency)
+		}
+		if plan.Period != "" && !slices.Contains(models.BillingPeriods, plan.Period) {
+			return fmt.Sprintf("套餐 %s 的计费周期 %q 无效，可选 %s", plan.Name, plan.Period, strings.Join(models.BillingPeriods, "、"))
+		}
+	}
+	if pricing.UpdatedAt != "" && !datePattern.MatchString(pricing.UpdatedAt) {
+		return "定价的更新日期必须是 2006-01-02 格式"
+	}
+	for _, record := range pricing.History {
+		if !datePattern.MatchString(record.Until) || (record.Since != "" && !datePattern.MatchString(record.Since)) {
+			return "价格历史中的日期必须是 2006-01-02 格式"
+		}
+	}
+	return ""
+}
+
+// ValidateSite 按 siteSchema 校验单个站点
+func ValidateSite(site models.Site) ValidationErrors {
+	var errs ValidationErrors
+	for _, rule := range siteSchema {
+		if reason := rule.Check(site); reason != "" {
+			errs = append(errs, ValidationError{Index: -1, SiteID: site.ID, Field: rule.Field, Reason: reason})
+		}
+	}
+	return errs
+}
+
+// validateSites 校验文件中的全部站点，已删除的站点只检查 ID
+func validateSites(file string, sites []models.Site) ValidationErrors {
+	var errs ValidationErrors
+	seen := make(map[string]int)
+
+	for i, site := range sites {
+		if site.ID != "" {
+			if first, ok := seen[site.ID]; ok {
+				errs = append(errs, ValidationError{File: file, Index: i, SiteID: site.ID, Field: "id",
+					Reason: fmt.Sprintf("ID 与第 %d 个站点重复", first)})
+			} else {
+				seen[site.ID] = i
+			}
+		}
+		if site.Deleted {
+			continue
+		}
+		for _, e := range ValidateSite(site) {
+			e.File, e.Index = file, i
+			errs = append(errs, e)
+		}
+	}
+	return errs
+}
+
+// decodeSitesJSON 解析站点 JSON，语法错误会转换为带行号的校验错误
+func decodeSitesJSON(file string, data []byte) ([]models.Site, error) {
+	var sites []models.Site
+	err := json.Unmarshal(data, &sites)
+	if err == nil {
+		return sites, nil
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		line := bytes.Count(data[:syntaxErr.Offset], []byte("\n")) + 1
+		return nil, ValidationErrors{{File: file, Index: -1, Reason: fmt.Sprintf("第 %d 行 JSON 语法错误: %v", line, err)}}
+	case errors.As(err, &typeErr):
+		line := bytes.Count(data[:typeErr.Offset], []byte("\n")) + 1
+		return nil, ValidationErrors{{File: file, Index: -1, Field: typeErr.Field,
+			Reason: fmt.Sprintf("第 %d 行字段 %s 类型错误，应为 %s", line, typeErr.Field, typeErr.Type)}}
+	default:
+		return nil, ValidationErrors{{File: file, Index: -1, Reason: err.Error()}}
+	}
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,173 @@
+package store
+
+import (
+	"crypto/sha256"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// watchDebounce 合并连续文件事件的等待时间，编辑器保存和 git pull 往往会产生一串事件
+	watchDebounce = 300 * time.Millisecond
+	// watchRearmInterval 数据目录被删除或重命名后重新建立监控的重试间隔
+	watchRearmInterval = time.Second
+)
+
+// jsonWatch JSONStore 的文件监控状态
+type jsonWatch struct {
+	mu      sync.Mutex
+	watcher *fsnotify.Watcher
+	stop    chan struct{}
+	// ownWrites 服务自身最近写入的文件内容摘要，用于忽略自己触发的事件
+	ownWrites map[string][sha256.Size]byte
+}
+
+// writeFile 原子写入数据文件并记录内容摘要，监控收到该次写入的事件时不会重复加载
+func (s *JSONStore) writeFile(path string, data []byte) error {
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return err
+	}
+
+	s.watch.mu.Lock()
+	if s.watch.ownWrites == nil {
+		s.watch.ownWrites = make(map[string][sha256.Size]byte)
+	}
+	s.watch.ownWrites[filepath.Clean(path)] = sha256.Sum256(data)
+	s.watch.mu.Unlock()
+	return nil
+}
+
+// Watch 监控数据目录而不是单个文件：编辑器和 git 通过重命名替换文件后监控依然有效，
+// 启动时尚不存在的 custom.json 也能被发现。一段时间内的多个事件只触发一次 onChange
+// 配置了远程站点目录时同时开始定期拉取。回调前清空缓存，之后的读取会重新加载文件
+func (s *JSONStore) Watch(onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	dir := s.dataDir
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return err
+	}
+
+	stop := make(chan struct{})
+	s.watch.mu.Lock()
+	s.watch.watcher = watcher
+	s.watch.stop = stop
+	s.watch.mu.Unlock()
+
+	reload := func() {
+		s.invalidate()
+		onChange()
+	}
+	go s.watchLoop(watcher, dir, stop, reload)
+	if s.remote != nil {
+		go s.remote.poll(stop, reload)
+	}
+	return nil
+}
+
+func (s *JSONStore) watchLoop(watcher *fsnotify.Watcher, dir string, stop chan struct{}, onChange func()) {
+	changed := make(map[string]bool)
+
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+	rearm := time.NewTicker(watchRearmInterval)
+	rearm.Stop()
+	defer rearm.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			name := filepath.Clean(event.Name)
+			if name == filepath.Clean(dir) && event.Has(fsnotify.Remove|fsnotify.Rename) {
+				log.Printf("数据目录 %s 被移除，等待重新监控", dir)
+				rearm.Reset(watchRearmInterval)
+				continue
+			}
+			if filepath.Dir(name) != filepath.Clean(dir) || !s.isWatchedFile(filepath.Base(name)) || event.Op == fsnotify.Chmod {
+				continue
+			}
+			changed[name] = true
+			debounce.Reset(watchDebounce)
+		case <-debounce.C:
+			if s.externallyChanged(changed) {
+				log.Println("检测到文件变更，重新加载数据")
+				onChange()
+			}
+			changed = make(map[string]bool)
+		case <-rearm.C:
+			if err := watcher.Add(dir); err != nil {
+				continue
+			}
+			rearm.Stop()
+			log.Printf("已重新监控数据目录 %s", dir)
+			onChange()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("监控错误: %v", err)
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				// 事件队列溢出时无法确定哪些文件变化，直接重新加载
+				onChange()
+			}
+		}
+	}
+}
+
+// isWatchedFile 判断数据目录中的文件变化是否需要重新加载
+func (s *JSONStore) isWatchedFile(name string) bool {
+	return name == customFile || name == categoriesFile || name == tagsFile || s.isPackFile(name)
+}
+
+// externallyChanged 判断变更的文件中是否有不是由服务自身写入的
+func (s *JSONStore) externallyChanged(changed map[string]bool) bool {
+	s.watch.mu.Lock()
+	defer s.watch.mu.Unlock()
+
+	for name := range changed {
+		own, ok := s.watch.ownWrites[name]
+		if !ok {
+			return true
+		}
+		data, err := os.ReadFile(name)
+		if err != nil || sha256.Sum256(data) != own {
+			return true
+		}
+	}
+	return false
+}
+
+// Close 停止监控，并等待已提交的修改全部写入 custom.json
+func (s *JSONStore) Close() error {
+	err := s.stopWatch()
+	s.flushWrites()
+	return err
+}
+
+func (s *JSONStore) stopWatch() error {
+	s.watch.mu.Lock()
+	defer s.watch.mu.Unlock()
+
+	if s.watch.watcher == nil {
+		return nil
+	}
+	close(s.watch.stop)
+	err := s.watch.watcher.Close()
+	s.watch.watcher = nil
+	return err
+}
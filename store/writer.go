@@ -0,0 +1,262 @@
+package store
+
+import (
+	"ai-navigator/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"sync"
+	"time"
+)
+
+// writeQueueSize 写入队列的容量，队列满时提交修改的请求会等待
+const writeQueueSize = 64
+
+// errStoreClosed 存储已关闭，不再接受修改
+var errStoreClosed = errors.New("存储已关闭")
+
+// Publisher 写入后直接提供最新站点列表的存储实现，调用方不需要在每次写入后重新读取全部数据
+type Publisher interface {
+	// OnWrite 注册写入完成后的回调，sites 与 List 的返回值相同；回调在提交修改的调用方返回之前执行
+	OnWrite(fn func(sites []models.Site))
+}
+
+// writeRequest 提交给写入协程的一次修改
+type writeRequest struct {
+	// fn 在自定义站点上执行修改并返回修改后的自定义站点，返回错误时该次修改不生效
+	fn   func(upstream, customSites []models.Site) ([]models.Site, error)
+	done chan error
+}
+
+// jsonWriter custom.json 的写入协程，所有修改都通过它串行执行
+// 协程在第一次提交修改时启动，只读取数据的场景（如命令行导出）不会启动
+type jsonWriter struct {
+	mu     sync.RWMutex
+	start  sync.Once
+	queue  chan writeRequest
+	done   chan struct{}
+	closed bool
+}
+
+// jsonState 最近一次读取或写入后的数据，读取时直接使用，不再重复解析文件
+type jsonState struct {
+	upstream    []models.Site
+	customSites []models.Site
+	overlay     overlayState
+	// stamp 缓存对应的 custom.json 的修改时间和大小，文件被其它程序改写后缓存失效
+	stamp fileStamp
+}
+
+// fileStamp 文件的修改时间和大小，文件不存在时为零值
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func (a fileStamp) equal(b fileStamp) bool {
+	return a.modTime.Equal(b.modTime) && a.size == b.size
+}
+
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+func (s *JSONStore) customStamp() fileStamp {
+	return statFile(s.customPath)
+}
+
+// invalidate 清空缓存，下一次读取时重新加载全部文件
+func (s *JSONStore) invalidate() {
+	s.mu.Lock()
+	s.cache = nil
+	s.mu.Unlock()
+}
+
+// submit 将修改交给写入协程并等待写入完成
+func (s *JSONStore) submit(fn func(upstream, customSites []models.Site) ([]models.Site, error)) error {
+	w := &s.writer
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		return errStoreClosed
+	}
+	w.start.Do(func() {
+		w.queue = make(chan writeRequest, writeQueueSize)
+		w.done = make(chan struct{})
+		go s.writeLoop()
+	})
+	req := writeRequest{fn: fn, done: make(chan error, 1)}
+	w.queue <- req
+	w.mu.RUnlock()
+
+	return <-req.done
+}
+
+// flushWrites 停止接受新的修改，等待队列中已提交的修改全部写入
+func (s *JSONStore) flushWrites() {
+	w := &s.writer
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	started := w.queue != nil
+	if started {
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	if started {
+		<-w.done
+	}
+}
+
+// writeLoop 每次取出队列中已有的全部修改，合并为一次写入
+func (s *JSONStore) writeLoop() {
+	defer close(s.writer.done)
+
+	for req := range s.writer.queue {
+		batch := []writeRequest{req}
+	drain:
+		for {
+			select {
+			case next, ok := <-s.writer.queue:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, next)
+			default:
+				break drain
+			}
+		}
+		s.writeBatch(batch)
+	}
+}
+
+func (s *JSONStore) OnWrite(fn func(sites []models.Site)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onWrite = fn
+}
+
+// writeBatch 依次执行一批修改，全部执行完后只写入一次 custom.json
+// 写入后把新的站点列表交给 OnWrite 注册的回调，之后才通知提交方，提交方返回时快照已经更新
+func (s *JSONStore) writeBatch(batch []writeRequest) {
+	results, sites, publish := s.applyBatch(batch)
+	if publish != nil && sites != nil {
+		publish(sites)
+	}
+	for i, req := range batch {
+		req.done <- results[i]
+	}
+}
+
+// applyBatch 执行一批修改并写入，返回每个修改的结果、写入后的站点列表和发布回调，没有写入时站点列表为 nil
+// 每个修改单独校验，失败的修改不影响同一批中的其它修改；回调在释放 s.mu 之后执行，可以再读取存储
+func (s *JSONStore) applyBatch(batch []writeRequest) ([]error, []models.Site, func([]models.Site)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := make([]error, len(batch))
+	upstream, customSites, err := s.read()
+	if err != nil {
+		for i := range results {
+			results[i] = err
+		}
+		return results, nil, nil
+	}
+
+	applied := 0
+	for i, req := range batch {
+		// 修改可能调整补丁基准，失败时需要恢复
+		saved := s.overlay
+		s.overlay.bases = maps.Clone(saved.bases)
+
+		next, err := req.fn(upstream, cloneSites(customSites))
+		if err == nil {
+			next = dropUnchanged(next, upstream)
+			if errs := validateSites(customFile, next); len(errs) > 0 {
+				err = errs
+			}
+		}
+		if err != nil {
+			s.overlay = saved
+			results[i] = err
+			continue
+		}
+		customSites = next
+		applied++
+	}
+
+	if applied == 0 {
+		return results, nil, nil
+	}
+	if applied > 1 {
+		log.Printf("已将 %d 个修改合并为一次写入", applied)
+	}
+	if err := s.persist(upstream, customSites); err != nil {
+		for i := range results {
+			if results[i] == nil {
+				results[i] = err
+			}
+		}
+		return results, nil, nil
+	}
+
+	// 通常直接使用 persist 更新的缓存，更新缓存失败时才重新读取文件
+	upstream, customSites, err = s.read()
+	if err != nil {
+		log.Printf("读取写入后的站点数据失败: %v", err)
+		return results, nil, nil
+	}
+	return results, MergeSites(upstream, customSites), s.onWrite
+}
+
+// persist 写入 custom.json，并用写入的内容更新缓存，不需要再从磁盘读取，调用方需持有 s.mu
+func (s *JSONStore) persist(upstream, customSites []models.Site) error {
+	data, err := encodeCustom(customSites, upstream, s.overlay)
+	if err != nil {
+		s.cache = nil
+		return err
+	}
+	if err := s.writeCustom(data); err != nil {
+		s.cache = nil
+		return err
+	}
+
+	sites, overlay, err := decodeCustom(upstream, data)
+	if err != nil {
+		// 文件已经写入，下一次读取时从磁盘重新加载
+		log.Printf("更新缓存失败: %v", err)
+		s.cache = nil
+		return nil
+	}
+	s.overlay = overlay
+	s.cache = &jsonState{upstream: upstream, customSites: sites, overlay: overlay, stamp: s.customStamp()}
+	return nil
+}
+
+// decodeCustom 解析 custom.json 的内容并应用到站点包的数据上
+func decodeCustom(upstream []models.Site, data []byte) ([]models.Site, overlayState, error) {
+	customSites, err := decodeSitesJSON(customFile, data)
+	if err != nil {
+		return nil, overlayState{}, err
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, overlayState{}, fmt.Errorf("解析 custom.json 文件失败: %w", err)
+	}
+	return applyPatches(upstream, customSites, entries)
+}
+
+func cloneSites(sites []models.Site) []models.Site {
+	return append([]models.Site(nil), sites...)
+}
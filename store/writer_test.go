@@ -0,0 +1,117 @@
+package store
+
+import (
+	"ai-navigator/config"
+	"ai-navigator/models"
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// holdWriter 创建写入队列但暂不启动写入协程，返回的函数启动协程
+// 在此之前提交的修改都留在队列中，协程启动后作为同一批写入
+func holdWriter(s *JSONStore) (release func()) {
+	w := &s.writer
+	w.start.Do(func() {
+		w.queue = make(chan writeRequest, writeQueueSize)
+		w.done = make(chan struct{})
+	})
+	return func() { go s.writeLoop() }
+}
+
+func TestWriteBatchCoalesces(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "local.json"), []byte(localCatalog), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, customFile), []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := NewJSONStore(config.StorageConfig{DataDir: dir})
+	defer s.Close()
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	// 写入方返回时已经收到写入后的站点列表
+	var published [][]models.Site
+	s.OnWrite(func(sites []models.Site) {
+		published = append(published, sites)
+	})
+
+	sites := []models.Site{
+		{ID: "a", Name: "A", URL: "https://a.example.com/"},
+		{ID: "b", Name: "B", URL: "not a url"},
+		{ID: "c", Name: "C", URL: "https://c.example.com/"},
+		{ID: "d", Name: "D", URL: "https://d.example.com/"},
+	}
+	release := holdWriter(s)
+	errs := make([]error, len(sites))
+	var wg sync.WaitGroup
+	for i, site := range sites {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = s.Create(site)
+		}()
+	}
+	// 全部进入队列后再启动写入协程
+	deadline := time.Now().Add(5 * time.Second)
+	for len(s.writer.queue) < len(sites) {
+		if time.Now().After(deadline) {
+			t.Fatalf("队列中只有 %d 个修改", len(s.writer.queue))
+		}
+		time.Sleep(time.Millisecond)
+	}
+	release()
+	wg.Wait()
+
+	for i, err := range errs {
+		if failed := sites[i].ID == "b"; failed != (err != nil) {
+			t.Fatalf("站点 %s 的错误 = %v", sites[i].ID, err)
+		}
+	}
+	var verr ValidationErrors
+	if !errors.As(errs[1], &verr) {
+		t.Fatalf("站点 b 的错误 = %v，应为校验错误", errs[1])
+	}
+
+	// 写入前的 custom.json 只备份一次，说明整批只写入了一次
+	backups, err := s.ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("备份数量 = %d，应只写入一次", len(backups))
+	}
+	if !strings.Contains(logs.String(), fmt.Sprintf("已将 %d 个修改合并为一次写入", len(sites)-1)) {
+		t.Fatalf("日志中没有合并写入的记录: %s", logs.String())
+	}
+
+	ids := listIDs(t, s)
+	slices.Sort(ids)
+	if strings.Join(ids, ",") != "a,c,d,local-a" {
+		t.Fatalf("站点 = %v，应写入 a、c、d", ids)
+	}
+
+	if len(published) != 1 {
+		t.Fatalf("发布了 %d 次站点列表，应只发布一次", len(published))
+	}
+	var publishedIDs []string
+	for _, site := range published[0] {
+		publishedIDs = append(publishedIDs, site.ID)
+	}
+	slices.Sort(publishedIDs)
+	if !slices.Equal(publishedIDs, ids) {
+		t.Fatalf("发布的站点 = %v，应与 List 一致 %v", publishedIDs, ids)
+	}
+}
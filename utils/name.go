@@ -0,0 +1,219 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// genericNameWords 站点名称中不能区分站点的通用词
+var genericNameWords = []string{"官方网站", "官网", "ai", "app", "chat", "official"}
+
+// SimilarNames 判断两个站点名称是否可能指同一站点：
+// 忽略大小写、空白、标点和通用词后相同，中文名称包含另一个名称（如「Kimi」和「Kimi 智能助手」），
+// 或者编辑距离足够小（如拼写差一个字母）
+func SimilarNames(a, b string) bool {
+	return NewNameKeys(a).Similar(NewNameKeys(b))
+}
+
+// NameKeys 预先计算的名称比较键，批量比较大量站点时每个名称只需计算一次
+type NameKeys struct {
+	raw string
+	key []rune
+}
+
+func NewNameKeys(name string) NameKeys {
+	return NameKeys{raw: nameKey(name, false), key: []rune(nameKey(name, true))}
+}
+
+// Similar 与 SimilarNames 的规则相同
+func (a NameKeys) Similar(b NameKeys) bool {
+	if a.raw != "" && a.raw == b.raw {
+		return true
+	}
+	x, y := a.key, b.key
+	if len(x) == 0 || len(y) == 0 {
+		return false
+	}
+
+	short, long := x, y
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+	if len(short) == len(long) && string(short) == string(long) {
+		return true
+	}
+	// 中英文混合的名称，如「Kimi」和「Kimi 智能助手」：较长的名称含有中文时才比较包含关系，
+	// 纯英文的「Claude」和「Claude Code」往往是同一厂商的不同产品
+	minLen := 4
+	if hasHan(short) {
+		minLen = 2
+	}
+	if len(short) >= minLen && hasHan(long) && strings.Contains(string(long), string(short)) {
+		return true
+	}
+
+	// 长度相差超过允许的编辑距离时不可能足够接近，跳过计算
+	limit := float64(len(long)) * 0.15
+	if len(short) < 6 || float64(len(long)-len(short)) > limit {
+		return false
+	}
+	return float64(editDistance(x, y)) <= limit
+}
+
+// maxPartLength 查找包含关系时名称片段的最大长度，更长的名称很少被其它名称包含
+const maxPartLength = 12
+
+// BlockHashes 返回名称的分组键哈希，Similar 为 true 的两个名称除包含关系外通常至少有一个键相同：
+// 完整名称相同（含不去掉通用词时）；编辑距离为 1，通过删除一个字后相同识别；长名称开头或结尾多出两个字。
+// 大量站点查找相似名称时只比较至少有一个键相同的站点，中间相差两个字的长名称不一定能分到同一组
+func (a NameKeys) BlockHashes() []uint64 {
+	var hashes []uint64
+	if a.raw != "" {
+		hashes = append(hashes, hashRunes('=', []rune(a.raw)))
+	}
+	n := len(a.key)
+	if n == 0 {
+		return hashes
+	}
+	hashes = append(hashes, a.KeyHash())
+	if n >= 6 {
+		hashes = append(hashes, hashRunes('d', a.key))
+		for i := range a.key {
+			hashes = append(hashes, hashRunes('d', a.key[:i], a.key[i+1:]))
+		}
+	}
+	// 足够长的名称允许编辑距离为 2，其中最常见的是在开头或结尾多出两个字，如「Stable Diffusion XL」
+	if n >= 14 {
+		hashes = append(hashes, hashRunes('d', a.key[:n-2]), hashRunes('d', a.key[2:]), hashRunes('d', a.key[1:n-1]))
+	}
+	return hashes
+}
+
+// KeyHash 去掉通用词后的完整名称的哈希，名称为空时为 0
+func (a NameKeys) KeyHash() uint64 {
+	if len(a.key) == 0 {
+		return 0
+	}
+	return hashRunes('k', a.key)
+}
+
+// CanBeContained 名称是否足够长，可以作为较短的一方参与包含关系的判断
+func (a NameKeys) CanBeContained() bool {
+	return len(a.key) >= 4 || (len(a.key) >= 2 && hasHan(a.key))
+}
+
+// EachPart 对含有中文的名称，依次以其中每个片段（长度 2 到 maxPartLength，不含完整名称）的 KeyHash 调用 fn，
+// 与其它名称的 KeyHash 比对即可找出被它包含的名称；不含中文的名称不参与包含关系的判断。
+// 片段只从中文字符或一段字母数字的开头开始，「kimi智能助手」中不会取出「imi」
+func (a NameKeys) EachPart(fn func(hash uint64)) {
+	n := len(a.key)
+	if !hasHan(a.key) {
+		return
+	}
+	for i := 0; i < n; i++ {
+		if i > 0 && !isHan(a.key[i]) && !isHan(a.key[i-1]) {
+			continue
+		}
+		h := newRuneHash('k')
+		for j := i; j < n && j-i < maxPartLength; j++ {
+			h.add(a.key[j])
+			if j-i >= 1 && j-i+1 < n {
+				fn(h.sum())
+			}
+		}
+	}
+}
+
+// runeHash 按字符累加的 FNV-1a 哈希，逐个追加字符即可得到每个前缀的哈希，不需要分配字符串
+type runeHash uint64
+
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+)
+
+func newRuneHash(kind rune) runeHash {
+	h := runeHash(fnvOffset)
+	h.add(kind)
+	return h
+}
+
+func (h *runeHash) add(r rune) {
+	for shift := 0; shift < 32; shift += 8 {
+		*h ^= runeHash(byte(r >> shift))
+		*h *= fnvPrime
+	}
+}
+
+func (h runeHash) sum() uint64 {
+	return uint64(h)
+}
+
+func hashRunes(kind rune, parts ...[]rune) uint64 {
+	h := newRuneHash(kind)
+	for _, part := range parts {
+		for _, r := range part {
+			h.add(r)
+		}
+	}
+	return h.sum()
+}
+
+// nameKey 将名称转为小写，去掉除字母、数字以外的字符，dropGeneric 为 true 时同时去掉通用词
+func nameKey(name string, dropGeneric bool) string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if dropGeneric {
+			for _, generic := range genericNameWords {
+				// 中文没有空格分词，通用词可能直接接在名称后面
+				if word == generic || hasHan([]rune(generic)) {
+					word = strings.TrimSuffix(word, generic)
+				}
+			}
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, "")
+}
+
+func hasHan(s []rune) bool {
+	for _, r := range s {
+		if isHan(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHan(r rune) bool {
+	return unicode.Is(unicode.Han, r)
+}
+
+// editDistance 计算两个字符串的编辑距离
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
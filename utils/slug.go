@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// Slugify 将字符串转换为只包含小写字母、数字和连字符的标识
+// 非 ASCII 字符会被丢弃，结果可能为空
+func Slugify(s string) string {
+	var b strings.Builder
+	lastHyphen := true
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+			lastHyphen = false
+		case !lastHyphen:
+			b.WriteByte('-')
+			lastHyphen = true
+		}
+	}
+	return strings.Trim(b.String(), "-")
+}
+
+// SlugFromURL 根据网址主机名生成标识，去掉 www 前缀和顶级域名
+// 例如 https://www.qianwen.com/ 生成 qianwen
+func SlugFromURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if i := strings.LastIndex(host, "."); i > 0 {
+		host = host[:i]
+	}
+	return Slugify(host)
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// CleanTag 去掉标签首尾的空白，中间连续的空白合并为一个空格，英文标签（如 text to speech）的空格保留
+func CleanTag(tag string) string {
+	return strings.Join(strings.Fields(tag), " ")
+}
+
+// TagKey 比较标签时使用的键：忽略大小写、空白、连字符和下划线，
+// 「AI 对话」与「AI对话」、「Text-to-Speech」与「text to speech」相同，「C++」与「C#」不同
+func TagKey(tag string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(tag) {
+		if !unicode.IsSpace(r) && r != '-' && r != '_' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// SplitTags 将表单中以逗号（中英文均可）分隔的标签拆分为列表，整理空白并去掉空标签和 TagKey 相同的重复标签
+func SplitTags(s string) []string {
+	tags := []string{}
+	seen := make(map[string]bool)
+	for _, tag := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '，' }) {
+		tag = CleanTag(tag)
+		key := TagKey(tag)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+)
+
+// trackingParams 网址中常见的来源跟踪参数，不影响指向的页面
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "msclkid": true, "yclid": true,
+	"spm": true, "ref": true, "ref_src": true, "from": true, "_ga": true,
+}
+
+// isTrackingParam utm_ 开头的参数和 trackingParams 中的参数
+func isTrackingParam(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "utm_") || trackingParams[name]
+}
+
+// NormalizeURL 返回用于判断两个网址是否指向同一站点的键
+// 忽略协议、www 前缀、默认端口、末尾的斜杠、锚点和来源跟踪参数，例如
+// http://www.example.com/?utm_source=x 与 https://example.com 得到相同的结果
+func NormalizeURL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return strings.ToLower(strings.TrimSpace(rawURL))
+	}
+
+	key := URLHostPath(rawURL)
+	if query := cleanQuery(u.Query()); query != "" {
+		key += "?" + query
+	}
+	return key
+}
+
+// URLHostPath 返回网址的主机名和路径，忽略协议、www 前缀、默认端口、末尾的斜杠和查询参数
+// 用于发现可能重复的站点，比 NormalizeURL 更宽松
+func URLHostPath(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return strings.ToLower(strings.TrimSpace(rawURL))
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+	return host + strings.TrimRight(u.EscapedPath(), "/")
+}
+
+// CleanURL 整理后台填写的网址：协议和主机名转为小写，去掉锚点和来源跟踪参数
+// 无法解析的网址原样返回（去掉首尾空白）
+func CleanURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment, u.RawFragment = "", ""
+	if u.RawQuery != "" {
+		u.RawQuery = cleanQuery(u.Query())
+	}
+	return u.String()
+}
+
+// cleanQuery 去掉来源跟踪参数，其余参数按名称排序后编码
+func cleanQuery(query url.Values) string {
+	for name := range query {
+		if isTrackingParam(name) {
+			query.Del(name)
+		}
+	}
+	return query.Encode()
+}